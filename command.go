@@ -0,0 +1,886 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"wave-edit/dsp"
+	"wave-edit/wave"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var ErrUnknownCommand = errors.New("unknown command")
+var ErrUsage = errors.New("wrong arguments")
+var ErrNonFinite = errors.New("file has NaN or infinite samples")
+var ErrDuplicateTrack = errors.New("tracks would be saved to the same file")
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"help": {
+			usage: "help",
+			run:   helpCommand,
+		},
+		"split": {
+			usage: "split <input.wav> [output directory]",
+			run:   splitCommand,
+		},
+		"merge": {
+			usage: "merge <output.wav> <track.wav>...",
+			run:   mergeCommand,
+		},
+		"inst": {
+			usage: "inst <file.wav> [remove | note= tune= gain= low-note= high-note= low-velocity= high-velocity=]",
+			run:   instCommand,
+		},
+		"loudness": {
+			usage: "loudness <file.wav>",
+			run:   loudnessCommand,
+		},
+		"normalize": {
+			usage: "normalize <file.wav> <peak | true-peak | rms | loudness> <target> [ceiling]",
+			run:   normalizeCommand,
+		},
+		"clipping": {
+			usage: "clipping <file.wav> [threshold]",
+			run:   clippingCommand,
+		},
+		"declip": {
+			usage: "declip <file.wav> [cubic | ar] [threshold]",
+			run:   declipCommand,
+		},
+		"stats": {
+			usage: "stats <file.wav>...",
+			run:   statsCommand,
+		},
+		"convolve": {
+			usage: "convolve <file.wav> <impulse.wav> [wet=1 dry=1 pre-delay=0 tail]",
+			run:   convolveCommand,
+		},
+		"reverb": {
+			usage: "reverb <file.wav> [room=0.5 damping=0.5 wet=0.3 dry=1 width=1]",
+			run:   reverbCommand,
+		},
+		"delay": {
+			usage: "delay <file.wav> <beats> [feedback=0.4 wet=0.5 dry=1 low-pass=0 high-pass=0 ping-pong]",
+			run:   delayCommand,
+		},
+		"fade": {
+			usage: "fade <file.wav> <in | out> <start seconds> <end seconds> [linear | equal-power | log | exp | s-curve]",
+			run:   fadeCommand,
+		},
+		"join": {
+			usage: "join <output.wav> <crossfade seconds> <linear | equal-power | log | exp | s-curve> <input.wav>...",
+			run:   joinCommand,
+		},
+		"reverse": {
+			usage: "reverse <file.wav> [start seconds] [end seconds]",
+			run:   reverseCommand,
+		},
+		"varispeed": {
+			usage: "varispeed <file.wav> <seconds:speed>... [start seconds] [end seconds]",
+			run:   varispeedCommand,
+		},
+		"stretch": {
+			usage: "stretch <file.wav> <stretch | bpm=tempo> [start seconds] [end seconds]",
+			run:   stretchCommand,
+		},
+	}
+}
+
+func runCommand(name string, args []string) error {
+	cmd, ok := commands[name]
+	if !ok {
+		helpCommand(nil)
+		return ErrUnknownCommand
+	}
+
+	err := cmd.run(args)
+	if errors.Is(err, ErrUsage) {
+		return fmt.Errorf("usage: wave-edit %s", cmd.usage)
+	}
+
+	return err
+}
+
+func helpCommand(_ []string) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	fmt.Println("usage: wave-edit [command] [arguments]")
+	fmt.Println("without a command the editor window opens")
+	for _, name := range names {
+		fmt.Println("  wave-edit", commands[name].usage)
+	}
+
+	return nil
+}
+
+func splitCommand(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return ErrUsage
+	}
+
+	input := args[0]
+	outputDir := filepath.Dir(input)
+	if len(args) == 2 {
+		outputDir = args[1]
+	}
+
+	poly, err := openWave(input)
+	if err != nil {
+		return err
+	}
+
+	tracks, err := poly.SplitChannels()
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	paths, err := trackPaths(outputDir, base, poly.TrackNames())
+	if err != nil {
+		return err
+	}
+
+	for n, path := range paths {
+		err = saveWave(path, tracks[n])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(path)
+	}
+
+	return nil
+}
+
+// a file for each track named after it, numbering tracks whose names are empty or taken
+func trackPaths(outputDir, base string, names []string) ([]string, error) {
+	paths := make([]string, len(names))
+	taken := map[string]bool{}
+
+	for n, name := range names {
+		name = fileSafeName(name)
+		if name == "" || taken[strings.ToLower(name)] {
+			name = strings.TrimLeft(name+"_"+strconv.Itoa(n+1), "_")
+		}
+
+		// case insensitive file systems would still put two tracks in one file
+		if taken[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%s: %w", name, ErrDuplicateTrack)
+		}
+		taken[strings.ToLower(name)] = true
+
+		paths[n] = filepath.Join(outputDir, base+"_"+name+".wav")
+	}
+
+	return paths, nil
+}
+
+func mergeCommand(args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+
+	tracks := make([]*wave.WaveFile, len(args)-1)
+	for n, path := range args[1:] {
+		track, err := openWave(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		tracks[n] = track
+	}
+
+	merged, err := wave.MergeChannels(tracks, 0)
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], merged)
+}
+
+func fileSafeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+}
+
+func instCommand(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		if file.Instrument == nil {
+			fmt.Println("no instrument chunk")
+			return nil
+		}
+
+		inst := file.Instrument
+		fmt.Printf("note=%d tune=%d gain=%d low-note=%d high-note=%d low-velocity=%d high-velocity=%d\n",
+			inst.UnshiftedNote, inst.FineTune, inst.Gain, inst.LowNote, inst.HighNote, inst.LowVelocity, inst.HighVelocity)
+		return nil
+	}
+
+	if len(args) == 2 && args[1] == "remove" {
+		file.Instrument = nil
+		return saveWave(args[0], file)
+	}
+
+	if file.Instrument == nil {
+		file.Instrument = wave.NewInstrument(60)
+	}
+
+	for _, setting := range args[1:] {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return ErrUsage
+		}
+
+		// notes and velocities are unsigned, tuning and gain are signed
+		signed, signedErr := strconv.ParseInt(value, 10, 8)
+		unsigned, unsignedErr := strconv.ParseUint(value, 10, 8)
+
+		inst := file.Instrument
+		switch key {
+		case "note":
+			inst.UnshiftedNote, err = uint8(unsigned), unsignedErr
+		case "tune":
+			inst.FineTune, err = int8(signed), signedErr
+		case "gain":
+			inst.Gain, err = int8(signed), signedErr
+		case "low-note":
+			inst.LowNote, err = uint8(unsigned), unsignedErr
+		case "high-note":
+			inst.HighNote, err = uint8(unsigned), unsignedErr
+		case "low-velocity":
+			inst.LowVelocity, err = uint8(unsigned), unsignedErr
+		case "high-velocity":
+			inst.HighVelocity, err = uint8(unsigned), unsignedErr
+		default:
+			return ErrUsage
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	err = file.Instrument.Validate()
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
+
+func loudnessCommand(args []string) error {
+	if len(args) != 1 {
+		return ErrUsage
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	loudness, err := dsp.MeasureLoudness(file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("integrated: %.1f LUFS\n", loudness.Integrated)
+	fmt.Printf("momentary max: %.1f LUFS\n", loudness.MaxMomentary)
+	fmt.Printf("short-term max: %.1f LUFS\n", loudness.MaxShortTerm)
+	fmt.Printf("range: %.1f LU\n", loudness.Range)
+	fmt.Printf("true peak: %.1f dBTP\n", loudness.TruePeak)
+
+	return nil
+}
+
+var normalizeModes = map[string]dsp.NormalizeMode{
+	"peak":      dsp.NORMALIZE_PEAK,
+	"true-peak": dsp.NORMALIZE_TRUE_PEAK,
+	"rms":       dsp.NORMALIZE_RMS,
+	"loudness":  dsp.NORMALIZE_LOUDNESS,
+}
+
+func normalizeCommand(args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return ErrUsage
+	}
+
+	mode, ok := normalizeModes[args[1]]
+	if !ok {
+		return ErrUsage
+	}
+
+	target, err := strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	normalization := dsp.Normalization{Mode: mode, Target: target}
+	if len(args) == 4 {
+		normalization.Limit = true
+		normalization.Ceiling, err = strconv.ParseFloat(args[3], 64)
+		if err != nil {
+			return fmt.Errorf("ceiling: %w", err)
+		}
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	gain, err := dsp.Normalize(file, normalization)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("gain: %+.2f dB\n", gain)
+	return saveWave(args[0], file)
+}
+
+// clipped runs of a file, the threshold is an optional argument
+func findClipping(file *wave.WaveFile, args []string) ([]dsp.ClipRun, error) {
+	threshold := dsp.DEFAULT_CLIP_THRESHOLD
+	if len(args) > 0 {
+		var err error
+		threshold, err = strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("threshold: %w", err)
+		}
+	}
+
+	return dsp.DetectClipping(file, threshold, dsp.DEFAULT_CLIP_MIN_LENGTH)
+}
+
+func clippingCommand(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return ErrUsage
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	runs, err := findClipping(file, args[1:])
+	if err != nil {
+		return err
+	}
+
+	names := file.TrackNames()
+	for _, run := range runs {
+		fmt.Printf("%s %s %d samples\n", formatTime(run.Time(file.Fmt.SamplesPerSec)), names[run.Channel], run.Length)
+	}
+	fmt.Printf("%d clipped runs\n", len(runs))
+
+	return nil
+}
+
+func declipCommand(args []string) error {
+	if len(args) < 1 || len(args) > 3 {
+		return ErrUsage
+	}
+
+	method := dsp.DECLIP_AR
+	if len(args) > 1 {
+		switch args[1] {
+		case "cubic":
+			method = dsp.DECLIP_CUBIC
+		case "ar":
+			method = dsp.DECLIP_AR
+		default:
+			return ErrUsage
+		}
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	runs, err := findClipping(file, args[min(2, len(args)):])
+	if err != nil {
+		return err
+	}
+
+	gain, err := dsp.DeclipFile(file, runs, method)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d clipped runs repaired, gain: %+.2f dB\n", len(runs), gain)
+	return saveWave(args[0], file)
+}
+
+// seconds as minutes:seconds.milliseconds
+func formatTime(seconds float64) string {
+	minutes := int(seconds / 60)
+	return fmt.Sprintf("%d:%06.3f", minutes, seconds-float64(minutes)*60)
+}
+
+// exits with an error when a file is broken, for checking renders
+func statsCommand(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	broken := false
+	for _, path := range args {
+		file, err := openWave(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		stats, err := dsp.MeasureStats(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Printf("%s: %s, %d frames\n", path, formatTime(stats.Duration), stats.Frames)
+
+		names := file.TrackNames()
+		for channel, channelStats := range stats.Channels {
+			fmt.Printf("  %s: peak %.2f dBFS, true peak %.2f dBTP, rms %.2f dBFS, dc %+.5f, crest %.2f dB, zero crossings %.1f/s, clipped %d",
+				names[channel], channelStats.Peak, channelStats.TruePeak, channelStats.Rms, channelStats.DcOffset,
+				channelStats.CrestFactor, channelStats.ZeroCrossings, channelStats.Clipped)
+
+			if file.Fmt.Format.IsFloat() {
+				fmt.Printf(", non-finite %d", channelStats.NonFinite)
+			}
+			fmt.Println()
+		}
+
+		for a := range stats.Correlation {
+			for b := a + 1; b < len(stats.Correlation); b++ {
+				fmt.Printf("  correlation %s/%s: %+.3f\n", names[a], names[b], stats.Correlation[a][b])
+			}
+		}
+
+		if stats.NonFinite() > 0 {
+			fmt.Printf("  %s: %d\n", ErrNonFinite, stats.NonFinite())
+			broken = true
+		}
+	}
+
+	if broken {
+		return ErrNonFinite
+	}
+
+	return nil
+}
+
+// parse name=value settings into the numbers they point at, and name flags into the bools
+func parseSettings(args []string, numbers map[string]*float64, flags map[string]*bool) error {
+	for _, setting := range args {
+		if flag, ok := flags[setting]; ok {
+			*flag = true
+			continue
+		}
+
+		key, value, ok := strings.Cut(setting, "=")
+		number, known := numbers[key]
+		if !ok || !known {
+			return ErrUsage
+		}
+
+		var err error
+		*number, err = strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func convolveCommand(args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+
+	settings := dsp.ConvolutionSettings{Wet: 1, Dry: 1}
+	err := parseSettings(args[2:], map[string]*float64{
+		"wet":       &settings.Wet,
+		"dry":       &settings.Dry,
+		"pre-delay": &settings.PreDelay,
+	}, map[string]*bool{
+		"tail": &settings.Tail,
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	impulse, err := openWave(args[1])
+	if err != nil {
+		return fmt.Errorf("%s: %w", args[1], err)
+	}
+
+	err = dsp.ConvolveFile(file, impulse, settings)
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
+
+// lengthen a file by tail seconds and run a processor over the original part of it
+func processWithTail(path string, file *wave.WaveFile, processor dsp.Processor, tail float64) error {
+	length := file.Frames()
+	tailFrames := uint32(tail * float64(file.Fmt.SamplesPerSec))
+
+	err := file.InsertSilence(length, tailFrames)
+	if err != nil {
+		return err
+	}
+
+	err = applyProcessor(file, processor, 0, length, tailFrames)
+	if err != nil {
+		return err
+	}
+
+	return saveWave(path, file)
+}
+
+func reverbCommand(args []string) error {
+	if len(args) < 1 {
+		return ErrUsage
+	}
+
+	settings := dsp.ReverbSettings{RoomSize: 0.5, Damping: 0.5, Wet: 0.3, Dry: 1, Width: 1}
+	err := parseSettings(args[1:], map[string]*float64{
+		"room":    &settings.RoomSize,
+		"damping": &settings.Damping,
+		"wet":     &settings.Wet,
+		"dry":     &settings.Dry,
+		"width":   &settings.Width,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	reverb := dsp.NewReverb(settings, file.Fmt.SamplesPerSec, file.Fmt.Channels)
+	return processWithTail(args[0], file, reverb, dsp.ReverbTail(settings))
+}
+
+// the delay time is in beats of the file's tempo, as the effect timeline uses
+func delayCommand(args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+
+	beats, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return fmt.Errorf("beats: %w", err)
+	} else if !(beats >= 0) || math.IsInf(beats, 1) {
+		return ErrUsage
+	}
+
+	settings := dsp.DelaySettings{Feedback: 0.4, Wet: 0.5, Dry: 1}
+	err = parseSettings(args[2:], map[string]*float64{
+		"feedback":  &settings.Feedback,
+		"wet":       &settings.Wet,
+		"dry":       &settings.Dry,
+		"low-pass":  &settings.LowPass,
+		"high-pass": &settings.HighPass,
+	}, map[string]*bool{
+		"ping-pong": &settings.PingPong,
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	settings.Time = beats * secondsPerBeat(file)
+	delay, err := dsp.NewDelay(settings, file.Fmt.SamplesPerSec, file.Fmt.Channels)
+	if err != nil {
+		return err
+	}
+
+	return processWithTail(args[0], file, delay, dsp.DelayTail(settings))
+}
+
+var fadeCurves = map[string]wave.FadeCurve{
+	"linear":      wave.FADE_LINEAR,
+	"equal-power": wave.FADE_EQUAL_POWER,
+	"log":         wave.FADE_LOGARITHMIC,
+	"exp":         wave.FADE_EXPONENTIAL,
+	"s-curve":     wave.FADE_S_CURVE,
+}
+
+func fadeCommand(args []string) error {
+	if len(args) < 4 || len(args) > 5 {
+		return ErrUsage
+	}
+
+	curve := wave.FADE_LINEAR
+	if len(args) == 5 {
+		var ok bool
+		curve, ok = fadeCurves[args[4]]
+		if !ok {
+			return ErrUsage
+		}
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(file, args[2:4])
+	if err != nil {
+		return err
+	}
+
+	switch args[1] {
+	case "in":
+		err = file.FadeIn(start, end, curve)
+	case "out":
+		err = file.FadeOut(start, end, curve)
+	default:
+		return ErrUsage
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
+
+func joinCommand(args []string) error {
+	if len(args) < 4 {
+		return ErrUsage
+	}
+
+	curve, ok := fadeCurves[args[2]]
+	if !ok {
+		return ErrUsage
+	}
+
+	pieces := make([]*wave.WaveFile, len(args)-3)
+	for n, path := range args[3:] {
+		var err error
+		pieces[n], err = openWave(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	frames, err := parseTime(args[1], pieces[0].Fmt.SamplesPerSec)
+	if err != nil {
+		return fmt.Errorf("crossfade: %w", err)
+	}
+
+	joined, err := wave.ConcatenateWithFade(nil, frames, curve, pieces...)
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], joined)
+}
+
+// the frames between optional start and end times in seconds, the whole file without them
+func parseRange(file *wave.WaveFile, args []string) (uint32, uint32, error) {
+	start, end := uint32(0), file.Frames()
+
+	if len(args) > 0 {
+		var err error
+		start, err = parseTime(args[0], file.Fmt.SamplesPerSec)
+		if err != nil {
+			return 0, 0, fmt.Errorf("start: %w", err)
+		}
+	}
+
+	if len(args) > 1 {
+		var err error
+		end, err = parseTime(args[1], file.Fmt.SamplesPerSec)
+		if err != nil {
+			return 0, 0, fmt.Errorf("end: %w", err)
+		}
+	}
+
+	return start, end, nil
+}
+
+// a time in seconds as frames at rate, which has to be a number of frames a file can hold
+func parseTime(value string, rate uint32) (uint32, error) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	frames := seconds * float64(rate)
+	if !(frames >= 0 && frames <= math.MaxUint32) {
+		return 0, ErrUsage
+	}
+
+	return uint32(frames), nil
+}
+
+func reverseCommand(args []string) error {
+	if len(args) < 1 || len(args) > 3 {
+		return ErrUsage
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(file, args[1:])
+	if err != nil {
+		return err
+	}
+
+	err = file.Reverse(start, end)
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
+
+func varispeedCommand(args []string) error {
+	if len(args) < 2 {
+		return ErrUsage
+	}
+
+	// speed points come first, then the range
+	count := slices.IndexFunc(args[1:], func(arg string) bool {
+		return !strings.Contains(arg, ":")
+	})
+	if count == -1 {
+		count = len(args) - 1
+	} else if count == 0 || len(args)-1-count > 2 {
+		return ErrUsage
+	}
+
+	points := make([]wave.SpeedPoint, count)
+	for n, arg := range args[1 : 1+count] {
+		time, speed, _ := strings.Cut(arg, ":")
+
+		var err error
+		points[n].Time, err = strconv.ParseFloat(time, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+
+		points[n].Speed, err = strconv.ParseFloat(speed, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", arg, err)
+		}
+	}
+
+	slices.SortStableFunc(points, func(a, b wave.SpeedPoint) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(file, args[1+count:])
+	if err != nil {
+		return err
+	}
+
+	err = file.Varispeed(start, end, wave.SpeedBreakpoints(points...))
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
+
+// a bpm over the whole file also sets its tempo, over part of it the file keeps its tempo
+func stretchCommand(args []string) error {
+	if len(args) < 2 || len(args) > 4 {
+		return ErrUsage
+	}
+
+	file, err := openWave(args[0])
+	if err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(file, args[2:])
+	if err != nil {
+		return err
+	}
+
+	bpm, isTempo := strings.CutPrefix(args[1], "bpm=")
+	if !isTempo {
+		stretch, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return fmt.Errorf("stretch: %w", err)
+		}
+
+		err = file.TimeStretch(start, end, stretch)
+		if err != nil {
+			return err
+		}
+
+		return saveWave(args[0], file)
+	}
+
+	tempo, err := strconv.ParseFloat(bpm, 64)
+	if err != nil {
+		return fmt.Errorf("bpm: %w", err)
+	}
+
+	if start == 0 && end == file.Frames() {
+		err = file.FitTempo(tempo)
+	} else if file.Acid == nil || !file.Acid.HasTempo() {
+		err = wave.ErrNoTempo
+	} else {
+		err = file.TimeStretch(start, end, float64(file.Acid.Tempo)/tempo)
+	}
+	if err != nil {
+		return err
+	}
+
+	return saveWave(args[0], file)
+}
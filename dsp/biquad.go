@@ -0,0 +1,129 @@
+package dsp
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// a second order IIR filter, its state carries across calls to Process
+type Biquad struct {
+	B0, B1, B2 float64 // Feedforward coefficients
+	A1, A2     float64 // Feedback coefficients, a0 is normalised to 1
+	z1, z2     float64
+}
+
+// filter samples in place
+func (filter *Biquad) Process(samples []float64) {
+	b0, b1, b2, a1, a2 := filter.B0, filter.B1, filter.B2, filter.A1, filter.A2
+	z1, z2 := filter.z1, filter.z2
+
+	// transposed direct form II
+	for n, in := range samples {
+		out := b0*in + z1
+		z1 = b1*in - a1*out + z2
+		z2 = b2*in - a2*out
+		samples[n] = out
+	}
+
+	filter.z1, filter.z2 = z1, z2
+}
+
+func (filter *Biquad) ProcessSample(in float64) float64 {
+	out := filter.B0*in + filter.z1
+	filter.z1 = filter.B1*in - filter.A1*out + filter.z2
+	filter.z2 = filter.B2*in - filter.A2*out
+
+	return out
+}
+
+// forget the filter state, as if only silence came before
+func (filter *Biquad) Reset() {
+	filter.z1, filter.z2 = 0, 0
+}
+
+// gain of the filter at a frequency in dB
+func (filter *Biquad) Response(frequency float64, samplesPerSec uint32) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*frequency/float64(samplesPerSec)))
+	numerator := complex(filter.B0, 0) + complex(filter.B1, 0)*z + complex(filter.B2, 0)*z*z
+	denominator := 1 + complex(filter.A1, 0)*z + complex(filter.A2, 0)*z*z
+
+	return LinearToDb(cmplx.Abs(numerator / denominator))
+}
+
+type FilterType int
+
+const (
+	FILTER_LOW_PASS FilterType = iota
+	FILTER_HIGH_PASS
+	FILTER_BAND_PASS // Unity gain at the centre frequency
+	FILTER_NOTCH
+	FILTER_PEAKING
+	FILTER_LOW_SHELF
+	FILTER_HIGH_SHELF
+)
+
+// Q giving a maximally flat second order response
+const BUTTERWORTH_Q = math.Sqrt2 / 2
+
+// design a filter from the Audio EQ Cookbook by Robert Bristow-Johnson
+// gain is only used by peaking and shelving filters, the shelf slope is fixed by q
+func NewBiquad(filterType FilterType, frequency, q, gainDb float64, samplesPerSec uint32) Biquad {
+	// keep the frequency inside the range the bilinear transform can map
+	frequency = max(1e-3, min(frequency, 0.499*float64(samplesPerSec)))
+
+	w0 := 2 * math.Pi * frequency / float64(samplesPerSec)
+	cos, sin := math.Cos(w0), math.Sin(w0)
+	alpha := sin / (2 * q)
+	a := math.Pow(10, gainDb/40)
+
+	var b0, b1, b2, a0, a1, a2 float64
+
+	switch filterType {
+	case FILTER_LOW_PASS:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FILTER_HIGH_PASS:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FILTER_BAND_PASS:
+		b0, b1, b2 = alpha, 0, -alpha
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FILTER_NOTCH:
+		b0, b1, b2 = 1, -2*cos, 1
+		a0, a1, a2 = 1+alpha, -2*cos, 1-alpha
+	case FILTER_PEAKING:
+		b0, b1, b2 = 1+alpha*a, -2*cos, 1-alpha*a
+		a0, a1, a2 = 1+alpha/a, -2*cos, 1-alpha/a
+	case FILTER_LOW_SHELF:
+		shelf := 2 * math.Sqrt(a) * alpha
+		b0, b1, b2 = a*((a+1)-(a-1)*cos+shelf), 2*a*((a-1)-(a+1)*cos), a*((a+1)-(a-1)*cos-shelf)
+		a0, a1, a2 = (a+1)+(a-1)*cos+shelf, -2*((a-1)+(a+1)*cos), (a+1)+(a-1)*cos-shelf
+	case FILTER_HIGH_SHELF:
+		shelf := 2 * math.Sqrt(a) * alpha
+		b0, b1, b2 = a*((a+1)+(a-1)*cos+shelf), -2*a*((a-1)+(a+1)*cos), a*((a+1)+(a-1)*cos-shelf)
+		a0, a1, a2 = (a+1)-(a-1)*cos+shelf, 2*((a-1)-(a+1)*cos), (a+1)-(a-1)*cos-shelf
+	default:
+		// pass everything through
+		b0, a0 = 1, 1
+	}
+
+	return Biquad{
+		B0: b0 / a0,
+		B1: b1 / a0,
+		B2: b2 / a0,
+		A1: a1 / a0,
+		A2: a2 / a0,
+	}
+}
+
+// a first order low or high pass, as a biquad with no second order terms
+func newFirstOrder(filterType FilterType, frequency float64, samplesPerSec uint32) Biquad {
+	frequency = max(1e-3, min(frequency, 0.499*float64(samplesPerSec)))
+	k := math.Tan(math.Pi * frequency / float64(samplesPerSec))
+
+	if filterType == FILTER_HIGH_PASS {
+		return Biquad{B0: 1 / (1 + k), B1: -1 / (1 + k), A1: (k - 1) / (k + 1)}
+	}
+
+	return Biquad{B0: k / (1 + k), B1: k / (1 + k), A1: (k - 1) / (k + 1)}
+}
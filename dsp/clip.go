@@ -0,0 +1,381 @@
+package dsp
+
+import (
+	"math"
+	"slices"
+	"wave-edit/wave"
+)
+
+const DEFAULT_CLIP_THRESHOLD = 0.99 // linear, fraction of full scale
+const DEFAULT_CLIP_MIN_LENGTH = 3   // samples
+
+type DeclipMethod int
+
+const (
+	DECLIP_CUBIC DeclipMethod = iota // Cubic through the samples either side
+	DECLIP_AR                        // Least squares autoregressive interpolation
+)
+
+// samples either side of a run used to reconstruct it
+const declipContext = 128
+const declipArOrder = 16
+
+// longer runs fall back to cubic, as the AR system grows with the square of the length
+const declipMaxArLength = 1024
+
+// consecutive samples of one channel stuck at or near full scale
+type ClipRun struct {
+	Channel uint16
+	Start   uint32 // Frame of the first clipped sample
+	Length  uint32 // Samples
+}
+
+// when the run starts in seconds
+func (run ClipRun) Time(samplesPerSec uint32) float64 {
+	return float64(run.Start) / float64(samplesPerSec)
+}
+
+// tracks a run of clipped samples through a channel
+type clipFinder struct {
+	threshold float64
+	minLength uint32
+	start     uint32
+	length    uint32
+	sign      float64
+}
+
+func (finder *clipFinder) add(runs []ClipRun, channel uint16, frame uint32, sample float64) []ClipRun {
+	clipped := math.Abs(sample) >= finder.threshold
+	sign := math.Copysign(1, sample)
+
+	if finder.length > 0 && (!clipped || sign != finder.sign) {
+		runs = finder.end(runs, channel)
+	}
+
+	if clipped {
+		if finder.length == 0 {
+			finder.start, finder.sign = frame, sign
+		}
+		finder.length++
+	}
+
+	return runs
+}
+
+func (finder *clipFinder) end(runs []ClipRun, channel uint16) []ClipRun {
+	if finder.length >= finder.minLength {
+		runs = append(runs, ClipRun{channel, finder.start, finder.length})
+	}
+
+	finder.length = 0
+	return runs
+}
+
+// find runs of at least minLength samples of the same sign at or above threshold
+// the Channel of each run is left as zero
+func FindClipping(samples []float64, threshold float64, minLength uint32) []ClipRun {
+	runs := []ClipRun{}
+	finder := clipFinder{threshold: threshold, minLength: minLength}
+
+	for n, sample := range samples {
+		runs = finder.add(runs, 0, uint32(n), sample)
+	}
+
+	return finder.end(runs, 0)
+}
+
+// find clipped runs in every channel of a file, in order of where they end
+func DetectClipping(file *wave.WaveFile, threshold float64, minLength uint32) ([]ClipRun, error) {
+	runs := []ClipRun{}
+	finders := make([]clipFinder, file.Fmt.Channels)
+	for channel := range finders {
+		finders[channel] = clipFinder{threshold: threshold, minLength: minLength}
+	}
+
+	var start uint32
+	err := readBlocks(file, func(buffer [][]float64) {
+		for n := range planarFrames(buffer) {
+			for channel, samples := range buffer {
+				runs = finders[channel].add(runs, uint16(channel), start+uint32(n), samples[n])
+			}
+		}
+
+		start += uint32(planarFrames(buffer))
+	})
+
+	for channel := range finders {
+		runs = finders[channel].end(runs, uint16(channel))
+	}
+
+	return runs, err
+}
+
+// reconstruct clipped runs of a channel in place, runs are relative to samples
+func Declip(samples []float64, runs []ClipRun, method DeclipMethod) {
+	for _, run := range runs {
+		declipRun(samples, int(run.Start), int(run.Length), method)
+	}
+}
+
+// reconstruct clipped runs in a file, lowering the gain of integer files if the restored peaks need headroom
+// returns the gain applied in dB
+func DeclipFile(file *wave.WaveFile, runs []ClipRun, method DeclipMethod) (float64, error) {
+	restored := make([][]float64, len(runs))
+	peak := 0.0
+
+	for n, run := range runs {
+		contextStart := run.Start - min(run.Start, declipContext)
+		buffer := file.NewPlanarBuffer(run.Start - contextStart + run.Length + declipContext)
+
+		frames, err := file.ReadFrames(contextStart, buffer)
+		if err != nil {
+			return 0, err
+		}
+
+		samples := buffer[run.Channel][:frames]
+		offset := run.Start - contextStart
+		declipRun(samples, int(offset), int(run.Length), method)
+
+		restored[n] = samples[offset : offset+run.Length]
+		for _, sample := range restored[n] {
+			peak = max(peak, math.Abs(sample))
+		}
+	}
+
+	gain := 1.0
+	if peak > 1 && !file.Fmt.Format.IsFloat() {
+		gain = 1 / peak
+
+		err := ProcessFile(file, Gain(gain))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for n, run := range runs {
+		buffer := file.NewPlanarBuffer(run.Length)
+
+		_, err := file.ReadFrames(run.Start, buffer)
+		if err != nil {
+			return 0, err
+		}
+
+		for i, sample := range restored[n] {
+			buffer[run.Channel][i] = sample * gain
+		}
+
+		err = file.WriteFrames(run.Start, buffer)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return LinearToDb(gain), nil
+}
+
+func declipRun(samples []float64, start, length int, method DeclipMethod) {
+	end := start + length
+	clipped := make([]float64, length)
+	copy(clipped, samples[start:end])
+
+	before := min(start, declipContext)
+	after := min(len(samples)-end, declipContext)
+	window := samples[start-before : end+after]
+
+	// neighbouring runs clipped at the same level are unknown too
+	level := math.Inf(1)
+	for _, sample := range clipped {
+		level = min(level, math.Abs(sample))
+	}
+
+	unknown := make([]bool, len(window))
+	unknowns := 0
+	for n, sample := range window {
+		unknown[n] = (n >= before && n < before+length) || math.Abs(sample) >= level
+		if unknown[n] {
+			unknowns++
+		}
+	}
+
+	if method == DECLIP_AR && unknowns <= declipMaxArLength && len(window)-unknowns >= 4*declipArOrder {
+		arInterpolate(window, unknown, before, length, declipArOrder)
+	} else {
+		cubicInterpolate(samples, start, length)
+	}
+
+	// the true signal was at least as loud as where it clipped
+	for n, level := range clipped {
+		sample := samples[start+n]
+		if math.Signbit(sample) != math.Signbit(level) || math.Abs(sample) < math.Abs(level) {
+			samples[start+n] = level
+		}
+	}
+}
+
+// the cubic through the two samples either side of a run
+func cubicInterpolate(samples []float64, start, length int) {
+	xs := []float64{}
+	ys := []float64{}
+
+	for _, n := range []int{start - 2, start - 1, start + length, start + length + 1} {
+		if n >= 0 && n < len(samples) {
+			xs = append(xs, float64(n))
+			ys = append(ys, samples[n])
+		}
+	}
+
+	if len(xs) < 2 {
+		return
+	}
+
+	for n := start; n < start+length; n++ {
+		samples[n] = lagrange(xs, ys, float64(n))
+	}
+}
+
+func lagrange(xs, ys []float64, x float64) float64 {
+	var sum float64
+	for i := range xs {
+		term := ys[i]
+		for j := range xs {
+			if i != j {
+				term *= (x - xs[j]) / (xs[i] - xs[j])
+			}
+		}
+		sum += term
+	}
+
+	return sum
+}
+
+// fill the unknown samples by minimising the error of an AR model fitted to the known ones
+// only samples[start:start+length] are changed
+func arInterpolate(samples []float64, unknown []bool, start, length, order int) {
+	coefficients := arModel(samples, unknown, order)
+
+	// number the unknowns
+	index := make([]int, len(samples))
+	size := 0
+	for n := range samples {
+		index[n] = -1
+		if unknown[n] {
+			index[n] = size
+			size++
+		}
+	}
+
+	// unknown samples u solve (AᵤᵀAᵤ)u = -AᵤᵀAₖk, A being the prediction error filter
+	system := make([]float64, size*size)
+	rhs := make([]float64, size)
+
+	for t := order; t < len(samples); t++ {
+		// prediction error at t from the known samples
+		var known float64
+		for k, coefficient := range coefficients {
+			if !unknown[t-k] {
+				known += coefficient * samples[t-k]
+			}
+		}
+
+		for k, coefficient := range coefficients {
+			i := index[t-k]
+			if i < 0 {
+				continue
+			}
+
+			rhs[i] -= coefficient * known
+			for l, other := range coefficients {
+				if j := index[t-l]; j >= 0 {
+					system[i*size+j] += coefficient * other
+				}
+			}
+		}
+	}
+
+	if !solveSymmetric(system, rhs, size) {
+		return
+	}
+
+	for n := start; n < start+length; n++ {
+		samples[n] = rhs[index[n]]
+	}
+}
+
+// prediction error filter of the given order, with a leading 1, fitted by least squares
+// over the stretches where a sample and all it is predicted from are known
+func arModel(samples []float64, unknown []bool, order int) []float64 {
+	system := make([]float64, order*order)
+	rhs := make([]float64, order)
+	rows := 0
+
+	for t := order; t < len(samples); t++ {
+		if slices.Contains(unknown[t-order:t+1], true) {
+			continue
+		}
+
+		for i := range order {
+			rhs[i] -= samples[t] * samples[t-1-i]
+			for j := range order {
+				system[i*order+j] += samples[t-1-i] * samples[t-1-j]
+			}
+		}
+		rows++
+	}
+
+	// a little white noise keeps the fit stable on pure tones and silence
+	var energy float64
+	for i := range order {
+		energy += system[i*order+i]
+	}
+	for i := range order {
+		system[i*order+i] += energy/float64(order)*1e-9 + 1e-12
+	}
+
+	coefficients := make([]float64, order+1)
+	coefficients[0] = 1
+
+	if rows >= order && solveSymmetric(system, rhs, order) {
+		copy(coefficients[1:], rhs)
+	}
+
+	return coefficients
+}
+
+// solve a symmetric positive definite system in place by Cholesky, the solution replaces rhs
+func solveSymmetric(matrix, rhs []float64, size int) bool {
+	for i := range size {
+		for j := 0; j <= i; j++ {
+			sum := matrix[i*size+j]
+			for k := range j {
+				sum -= matrix[i*size+k] * matrix[j*size+k]
+			}
+
+			if i == j {
+				if sum <= 0 {
+					return false
+				}
+				matrix[i*size+i] = math.Sqrt(sum)
+			} else {
+				matrix[i*size+j] = sum / matrix[j*size+j]
+			}
+		}
+	}
+
+	for i := range size {
+		sum := rhs[i]
+		for k := range i {
+			sum -= matrix[i*size+k] * rhs[k]
+		}
+		rhs[i] = sum / matrix[i*size+i]
+	}
+
+	for i := size - 1; i >= 0; i-- {
+		sum := rhs[i]
+		for k := i + 1; k < size; k++ {
+			sum -= matrix[k*size+i] * rhs[k]
+		}
+		rhs[i] = sum / matrix[i*size+i]
+	}
+
+	return true
+}
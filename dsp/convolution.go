@@ -0,0 +1,229 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"wave-edit/wave"
+)
+
+// frames in each partition of the impulse response, and the latency of the convolver
+const convolutionBlock = 1024
+
+type ConvolutionSettings struct {
+	Wet      float64 // Linear gain of the convolved signal
+	Dry      float64 // Linear gain of the original signal
+	PreDelay float64 // Seconds of silence ahead of the impulse response
+	Tail     bool    // Lengthen the file so the reverb rings out
+}
+
+// one input channel feeding one output channel through one impulse response
+type convolutionPath struct {
+	input, output int
+	partitions    [][]complex128 // spectrum of each block of the impulse response
+}
+
+// uniformly partitioned overlap-save convolution of several channels
+type Convolver struct {
+	transform *fft
+	paths     []convolutionPath
+	dry, wet  float64
+
+	history  [][][]complex128 // spectra of recent input blocks for each input, newest at next
+	next     int
+	window   [][]float64 // last two blocks of each input
+	previous [][]float64 // the block before the one filling, for the delayed dry signal
+	output   [][]float64 // wet output of the last full block
+	position int
+	sum      []complex128
+}
+
+var ErrImpulseChannels = errors.New("impulse response channels do not map to the file")
+var ErrEmptyImpulse = errors.New("impulse response is empty")
+
+// a convolver from the planar impulse responses of each path
+// impulse responses map to channels as one for all, one per channel, or true stereo
+// true stereo impulses hold left to left, left to right, right to left and right to right in that order
+func NewConvolver(impulse [][]float64, channels uint16, settings ConvolutionSettings, samplesPerSec uint32) (*Convolver, error) {
+	if len(impulse) == 0 || len(impulse[0]) == 0 {
+		return nil, ErrEmptyImpulse
+	}
+
+	routes, err := convolutionRoutes(len(impulse), int(channels))
+	if err != nil {
+		return nil, err
+	}
+
+	preDelay := int(math.Round(settings.PreDelay * float64(samplesPerSec)))
+	length := preDelay + len(impulse[0])
+	partitions := (length + convolutionBlock - 1) / convolutionBlock
+
+	convolver := &Convolver{
+		transform: newFFT(2 * convolutionBlock),
+		dry:       settings.Dry,
+		wet:       settings.Wet,
+		history:   make([][][]complex128, channels),
+		window:    make([][]float64, channels),
+		previous:  make([][]float64, channels),
+		output:    make([][]float64, channels),
+		sum:       make([]complex128, 2*convolutionBlock),
+	}
+
+	for channel := range channels {
+		convolver.history[channel] = make([][]complex128, partitions)
+		for n := range partitions {
+			convolver.history[channel][n] = make([]complex128, 2*convolutionBlock)
+		}
+
+		convolver.window[channel] = make([]float64, 2*convolutionBlock)
+		convolver.previous[channel] = make([]float64, convolutionBlock)
+		convolver.output[channel] = make([]float64, convolutionBlock)
+	}
+
+	for _, route := range routes {
+		path := convolutionPath{input: route[0], output: route[1], partitions: make([][]complex128, partitions)}
+		response := impulse[route[2]]
+
+		for n := range partitions {
+			// each block of the response padded to twice its length
+			spectrum := make([]complex128, 2*convolutionBlock)
+			for i := range convolutionBlock {
+				if sample := n*convolutionBlock + i - preDelay; sample >= 0 && sample < len(response) {
+					spectrum[i] = complex(response[sample], 0)
+				}
+			}
+
+			convolver.transform.transform(spectrum, false)
+			path.partitions[n] = spectrum
+		}
+
+		convolver.paths = append(convolver.paths, path)
+	}
+
+	return convolver, nil
+}
+
+// input, output and impulse channel of each path
+func convolutionRoutes(impulseChannels, channels int) ([][3]int, error) {
+	routes := [][3]int{}
+
+	switch {
+	case impulseChannels == 1:
+		for channel := range channels {
+			routes = append(routes, [3]int{channel, channel, 0})
+		}
+	case impulseChannels == channels:
+		for channel := range channels {
+			routes = append(routes, [3]int{channel, channel, channel})
+		}
+	case impulseChannels == 4 && channels == 2:
+		routes = append(routes, [3]int{0, 0, 0}, [3]int{0, 1, 1}, [3]int{1, 0, 2}, [3]int{1, 1, 3})
+	default:
+		return nil, ErrImpulseChannels
+	}
+
+	return routes, nil
+}
+
+func (convolver *Convolver) Latency() uint32 {
+	return convolutionBlock
+}
+
+func (convolver *Convolver) Process(buffer [][]float64) {
+	for n := range planarFrames(buffer) {
+		for channel, samples := range buffer {
+			in := samples[n]
+			samples[n] = convolver.dry*convolver.previous[channel][convolver.position] +
+				convolver.wet*convolver.output[channel][convolver.position]
+			convolver.window[channel][convolutionBlock+convolver.position] = in
+		}
+
+		convolver.position++
+		if convolver.position == convolutionBlock {
+			convolver.processBlock()
+			convolver.position = 0
+		}
+	}
+}
+
+// convolve the block of input just filled
+func (convolver *Convolver) processBlock() {
+	partitions := len(convolver.history[0])
+	convolver.next = (convolver.next + partitions - 1) % partitions
+
+	for channel, window := range convolver.window {
+		spectrum := convolver.history[channel][convolver.next]
+		for n, sample := range window {
+			spectrum[n] = complex(sample, 0)
+		}
+		convolver.transform.transform(spectrum, false)
+
+		// slide the window along, keeping the dry block for output
+		copy(convolver.previous[channel], window[convolutionBlock:])
+		copy(window, window[convolutionBlock:])
+	}
+
+	for output := range convolver.output {
+		clear(convolver.sum)
+
+		for _, path := range convolver.paths {
+			if path.output != output {
+				continue
+			}
+
+			for n, partition := range path.partitions {
+				input := convolver.history[path.input][(convolver.next+n)%partitions]
+				for i, value := range partition {
+					convolver.sum[i] += input[i] * value
+				}
+			}
+		}
+
+		convolver.transform.transform(convolver.sum, true)
+
+		// the first half wraps around, the second is the convolution of the new block
+		for n := range convolutionBlock {
+			convolver.output[output][n] = real(convolver.sum[convolutionBlock+n])
+		}
+	}
+}
+
+// convolve a file in place with an impulse response, resampling the response to match
+func ConvolveFile(file *wave.WaveFile, impulse *wave.WaveFile, settings ConvolutionSettings) error {
+	if impulse.Frames() == 0 {
+		return ErrEmptyImpulse
+	}
+
+	// more samples of the same response would add up louder
+	gain := float64(impulse.Fmt.SamplesPerSec) / float64(file.Fmt.SamplesPerSec)
+
+	if impulse.Fmt.SamplesPerSec != file.Fmt.SamplesPerSec {
+		var err error
+		impulse, err = impulse.Resample(file.Fmt.SamplesPerSec)
+		if err != nil {
+			return err
+		}
+	}
+
+	response := impulse.NewPlanarBuffer(impulse.Frames())
+	_, err := impulse.ReadFrames(0, response)
+	if err != nil {
+		return err
+	}
+
+	Gain(gain).Process(response)
+
+	convolver, err := NewConvolver(response, file.Fmt.Channels, settings, file.Fmt.SamplesPerSec)
+	if err != nil {
+		return err
+	}
+
+	if settings.Tail {
+		preDelay := uint32(math.Round(settings.PreDelay * float64(file.Fmt.SamplesPerSec)))
+		err = file.InsertSilence(file.Frames(), preDelay+impulse.Frames())
+		if err != nil {
+			return err
+		}
+	}
+
+	return ProcessFile(file, convolver)
+}
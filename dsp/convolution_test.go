@@ -0,0 +1,124 @@
+package dsp
+
+import (
+	"testing"
+	"wave-edit/wave"
+)
+
+// the sum of every input channel convolved with the impulse routed to the output channel
+func directConvolution(input, impulse [][]float64, output int, preDelay int) []float64 {
+	routes, _ := convolutionRoutes(len(impulse), len(input))
+	result := make([]float64, len(input[0]))
+
+	for _, route := range routes {
+		if route[1] != output {
+			continue
+		}
+
+		for n := range result {
+			for k, tap := range impulse[route[2]] {
+				if in := n - k - preDelay; in >= 0 {
+					result[n] += tap * input[route[0]][in]
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func TestConvolverMatchesDirectConvolution(t *testing.T) {
+	tests := []struct {
+		name     string
+		channels int
+		impulses int
+		length   int // frames of each impulse, over a partition to test the partitioning
+		settings ConvolutionSettings
+	}{
+		{"mono", 1, 1, 2500, ConvolutionSettings{Wet: 1}},
+		{"one impulse for every channel", 2, 1, 3000, ConvolutionSettings{Wet: 0.5, Dry: 1}},
+		{"impulse per channel", 3, 3, 1500, ConvolutionSettings{Wet: 1, Dry: 0.25}},
+		{"true stereo", 2, 4, 2100, ConvolutionSettings{Wet: 1}},
+		{"shorter than a partition", 2, 2, 100, ConvolutionSettings{Wet: 1}},
+		{"exactly a partition", 1, 1, convolutionBlock, ConvolutionSettings{Wet: 1}},
+		{"pre delay", 2, 1, 1200, ConvolutionSettings{Wet: 1, Dry: 1, PreDelay: 0.01}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			impulse := make([][]float64, test.impulses)
+			for channel := range impulse {
+				impulse[channel] = noise(test.length, uint32(10+channel))
+			}
+
+			input := make([][]float64, test.channels)
+			for channel := range input {
+				input[channel] = noise(6000, uint32(20+channel))
+			}
+
+			convolver, err := NewConvolver(impulse, uint16(test.channels), test.settings, 48000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// uneven blocks so the partitions don't line up with calls
+			output := make([][]float64, test.channels)
+			for channel := range output {
+				output[channel] = append([]float64(nil), input[channel]...)
+			}
+			for start := 0; start < 6000; start += 700 {
+				convolver.Process(sliceFrames(output, uint32(start), uint32(min(6000, start+700))))
+			}
+
+			latency := int(convolver.Latency())
+			for channel := range output {
+				wet := directConvolution(input, impulse, channel, int(test.settings.PreDelay*48000))
+
+				for n := latency; n < 6000; n++ {
+					want := test.settings.Wet*wet[n-latency] + test.settings.Dry*input[channel][n-latency]
+					if diff := output[channel][n] - want; diff > 1e-9 || diff < -1e-9 {
+						t.Fatalf("channel %d frame %d: got %f, want %f", channel, n, output[channel][n], want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestConvolverErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		impulse  [][]float64
+		channels uint16
+		err      error
+	}{
+		{"no channels", [][]float64{}, 2, ErrEmptyImpulse},
+		{"no frames", [][]float64{{}}, 2, ErrEmptyImpulse},
+		{"too few channels", [][]float64{{1}, {1}}, 3, ErrImpulseChannels},
+		{"true stereo into mono", [][]float64{{1}, {1}, {1}, {1}}, 1, ErrImpulseChannels},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewConvolver(test.impulse, test.channels, ConvolutionSettings{Wet: 1}, 48000)
+			if err != test.err {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestConvolveFileTail(t *testing.T) {
+	file := sineFile(wave.PCM_FLOAT32, 2, 48000, 1000, -6, 0.1)
+	impulse := sineFile(wave.PCM_FLOAT32, 1, 48000, 100, -20, 0.05)
+	frames := file.Frames()
+
+	err := ConvolveFile(file, impulse, ConvolutionSettings{Wet: 1, PreDelay: 0.01, Tail: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := frames + 480 + impulse.Frames(); file.Frames() != want {
+		t.Errorf("got %d frames, want %d", file.Frames(), want)
+	}
+}
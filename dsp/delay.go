@@ -0,0 +1,108 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+)
+
+type DelaySettings struct {
+	Time     float64 // Seconds between repeats, beats times seconds per beat to follow the tempo
+	Feedback float64 // Linear gain of each repeat into the next, between -1 and 1 exclusive
+	Wet      float64 // Linear gain of the repeats
+	Dry      float64 // Linear gain of the original signal
+	PingPong bool    // Repeats bounce from channel to channel
+	LowPass  float64 // Hz, filters the repeats a little more each time, 0 for none
+	HighPass float64 // Hz, as LowPass
+}
+
+// a feedback delay line for each channel
+type Delay struct {
+	settings DelaySettings
+	lines    [][]float64
+	next     int
+	filters  []Cascade
+	delayed  []float64
+}
+
+var ErrInvalidDelay = errors.New("delay time must not be negative, and feedback must be between -1 and 1")
+
+func NewDelay(settings DelaySettings, samplesPerSec uint32, channels uint16) (*Delay, error) {
+	// feedback of 1 or more never dies away
+	if !(settings.Time >= 0) || math.IsInf(settings.Time, 1) || !(math.Abs(settings.Feedback) < 1) {
+		return nil, ErrInvalidDelay
+	}
+
+	length := max(1, int(math.Round(settings.Time*float64(samplesPerSec))))
+
+	filter := Cascade{}
+	if settings.LowPass > 0 {
+		filter = append(filter, NewBiquad(FILTER_LOW_PASS, settings.LowPass, BUTTERWORTH_Q, 0, samplesPerSec))
+	}
+	if settings.HighPass > 0 {
+		filter = append(filter, NewBiquad(FILTER_HIGH_PASS, settings.HighPass, BUTTERWORTH_Q, 0, samplesPerSec))
+	}
+
+	delay := &Delay{
+		settings: settings,
+		lines:    make([][]float64, channels),
+		filters:  NewFilter(filter, channels).channels,
+		delayed:  make([]float64, channels),
+	}
+
+	for channel := range delay.lines {
+		delay.lines[channel] = make([]float64, length)
+	}
+
+	return delay, nil
+}
+
+// seconds for the repeats to fall by 60dB
+func DelayTail(settings DelaySettings) float64 {
+	if settings.Feedback == 0 {
+		return settings.Time
+	}
+
+	repeats := 3 / -math.Log10(min(math.Abs(settings.Feedback), 0.999))
+	return settings.Time * (1 + repeats)
+}
+
+func (delay *Delay) Latency() uint32 {
+	return 0
+}
+
+func (delay *Delay) Process(buffer [][]float64) {
+	channels := len(buffer)
+
+	for n := range planarFrames(buffer) {
+		for channel, line := range delay.lines {
+			delay.delayed[channel] = line[delay.next]
+		}
+
+		// ping pong feeds the mixed input into the first line, which hands each repeat on to the next
+		var input float64
+		if delay.settings.PingPong {
+			for _, samples := range buffer {
+				input += samples[n]
+			}
+			input /= float64(channels)
+		}
+
+		for channel, samples := range buffer {
+			source, in := channel, samples[n]
+			if delay.settings.PingPong {
+				source = (channel + channels - 1) % channels
+				in = 0
+				if channel == 0 {
+					in = input
+				}
+			}
+
+			repeat := delay.filters[channel].ProcessSample(delay.delayed[source])
+			delay.lines[channel][delay.next] = in + delay.settings.Feedback*repeat
+
+			samples[n] = samples[n]*delay.settings.Dry + delay.delayed[channel]*delay.settings.Wet
+		}
+
+		delay.next = (delay.next + 1) % len(delay.lines[0])
+	}
+}
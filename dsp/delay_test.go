@@ -0,0 +1,144 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// the wet output of a delay for an impulse in the first channel
+func delayImpulse(t *testing.T, settings DelaySettings, channels uint16, length int) [][]float64 {
+	t.Helper()
+
+	delay, err := NewDelay(settings, 1000, channels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([][]float64, channels)
+	for channel := range buffer {
+		buffer[channel] = make([]float64, length)
+	}
+	buffer[0][0] = 1
+
+	// in two blocks, so repeats carry over between them
+	for _, block := range [][2]int{{0, length / 3}, {length / 3, length}} {
+		part := make([][]float64, channels)
+		for channel := range part {
+			part[channel] = buffer[channel][block[0]:block[1]]
+		}
+		delay.Process(part)
+	}
+
+	return buffer
+}
+
+func TestDelayRepeats(t *testing.T) {
+	tests := []struct {
+		name     string
+		feedback float64
+		repeats  []float64 // every 10 samples, from the first repeat
+	}{
+		{"no feedback", 0, []float64{1, 0, 0, 0}},
+		{"half", 0.5, []float64{1, 0.5, 0.25, 0.125}},
+		{"inverted", -0.5, []float64{1, -0.5, 0.25, -0.125}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := delayImpulse(t, DelaySettings{Time: 0.01, Feedback: test.feedback, Wet: 1}, 1, 50)
+
+			for n, sample := range output[0] {
+				want := 0.0
+				if n%10 == 0 && n > 0 {
+					want = test.repeats[n/10-1]
+				}
+
+				if math.Abs(sample-want) > 1e-12 {
+					t.Errorf("sample %d: got %g, want %g", n, sample, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDelayPingPong(t *testing.T) {
+	output := delayImpulse(t, DelaySettings{Time: 0.01, Feedback: 0.5, Wet: 1, PingPong: true}, 2, 50)
+
+	// the mixed input starts on the first channel and each repeat moves to the other
+	want := [][]float64{{0.5, 0, 0.125, 0}, {0, 0.25, 0, 0.0625}}
+	for channel, samples := range output {
+		for n, sample := range samples {
+			expected := 0.0
+			if n%10 == 0 && n > 0 {
+				expected = want[channel][n/10-1]
+			}
+
+			if math.Abs(sample-expected) > 1e-12 {
+				t.Errorf("channel %d sample %d: got %g, want %g", channel, n, sample, expected)
+			}
+		}
+	}
+}
+
+func TestDelayDry(t *testing.T) {
+	output := delayImpulse(t, DelaySettings{Time: 0.01, Feedback: 0.5, Dry: 1}, 1, 50)
+
+	for n, sample := range output[0] {
+		want := 0.0
+		if n == 0 {
+			want = 1
+		}
+
+		if sample != want {
+			t.Errorf("sample %d: got %g, want %g", n, sample, want)
+		}
+	}
+}
+
+func TestNewDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     float64
+		feedback float64
+		want     error
+	}{
+		{"just below 1", 0.1, 0.99, nil},
+		{"just above -1", 0.1, -0.99, nil},
+		{"no time", 0, 0.5, nil},
+		{"feedback of 1", 0.1, 1, ErrInvalidDelay},
+		{"feedback of -1", 0.1, -1, ErrInvalidDelay},
+		{"feedback past 1", 0.1, 1.5, ErrInvalidDelay},
+		{"feedback not a number", 0.1, math.NaN(), ErrInvalidDelay},
+		{"negative time", -0.1, 0.5, ErrInvalidDelay},
+		{"infinite time", math.Inf(1), 0.5, ErrInvalidDelay},
+		{"time not a number", math.NaN(), 0.5, ErrInvalidDelay},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewDelay(DelaySettings{Time: test.time, Feedback: test.feedback}, 44100, 2)
+			if err != test.want {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestDelayTail(t *testing.T) {
+	tests := []struct {
+		name     string
+		feedback float64
+		want     float64
+	}{
+		{"no feedback", 0, 0.5},
+		{"a tenth", 0.1, 2},
+		{"inverted tenth", -0.1, 2},
+		{"a thousandth", 0.001, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			closeTo(t, "tail", DelayTail(DelaySettings{Time: 0.5, Feedback: test.feedback}), test.want, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wave-edit/wave"
+)
+
+// a file of a sine in every channel, amplitude in dBFS
+func sineFile(format wave.WaveFormat, channels uint16, samplesPerSec uint32, frequency, amplitudeDb, seconds float64) *wave.WaveFile {
+	file := wave.CreateWave(format, channels, samplesPerSec)
+	file.InsertSilence(0, uint32(seconds*float64(samplesPerSec)))
+	appendSine(file, 0, frequency, amplitudeDb, 0)
+	return file
+}
+
+// write a sine from start to the end of the file, starting at a phase in radians
+func appendSine(file *wave.WaveFile, start uint32, frequency, amplitudeDb, phase float64) {
+	buffer := file.NewPlanarBuffer(file.Frames() - start)
+	amplitude := DbToLinear(amplitudeDb)
+
+	for channel := range buffer {
+		for n := range buffer[channel] {
+			buffer[channel][n] = amplitude * math.Sin(2*math.Pi*frequency*float64(n)/float64(file.Fmt.SamplesPerSec)+phase)
+		}
+	}
+
+	file.WriteFrames(start, buffer)
+}
+
+// repeatable noise in [-1, 1]
+func noise(length int, seed uint32) []float64 {
+	samples := make([]float64, length)
+	for n := range samples {
+		seed = seed*1664525 + 1013904223
+		samples[n] = float64(seed)/math.MaxUint32*2 - 1
+	}
+
+	return samples
+}
+
+func closeTo(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+
+	if math.IsNaN(got) || math.Abs(got-want) > tolerance {
+		t.Errorf("%s: got %.4f, want %.4f within %g", name, got, want, tolerance)
+	}
+}
@@ -0,0 +1,282 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"wave-edit/wave"
+)
+
+type DynamicsType int
+
+const (
+	DYNAMICS_COMPRESSOR DynamicsType = iota // Turns down what goes above the threshold
+	DYNAMICS_EXPANDER                       // Turns down what falls below the threshold
+	DYNAMICS_GATE                           // Shuts off what falls below the threshold
+)
+
+type DynamicsSettings struct {
+	Type      DynamicsType
+	Threshold float64 // dBFS
+	Ratio     float64 // Input dB per output dB above the threshold, or output dB per input dB below it for expanders
+	Knee      float64 // dB width of the soft knee around the threshold, 0 for a hard knee
+	Attack    float64 // Seconds to react as the level rises
+	Release   float64 // Seconds to recover as the level falls
+	Hold      float64 // Seconds a gate or expander stays open once the level falls
+	Range     float64 // Most a gate or expander turns down in dB, 0 for no limit
+	Makeup    float64 // dB added after processing
+	Link      bool    // Apply the same gain to every channel, from the loudest
+}
+
+// a feed-forward dynamics processor, by default keyed from its own input
+type Dynamics struct {
+	settings  DynamicsSettings
+	attack    float64 // per frame smoothing coefficients
+	release   float64
+	decay     float64
+	hold      int
+	levels    []float64 // detector envelope of each gain, linear
+	reduction []float64 // smoothed gain reduction in dB, positive
+	holding   []int
+}
+
+// release of the peak detector ahead of the gain computer, long enough to ride over a cycle of bass
+const dynamicsDetectorRelease = 0.02 // seconds
+
+var ErrKeyChannels = errors.New("key has no channels")
+
+func NewDynamics(settings DynamicsSettings, samplesPerSec uint32, channels uint16) *Dynamics {
+	gains := int(channels)
+	if settings.Link {
+		gains = 1
+	}
+
+	return &Dynamics{
+		settings:  settings,
+		attack:    smoothingCoefficient(settings.Attack, samplesPerSec),
+		release:   smoothingCoefficient(settings.Release, samplesPerSec),
+		decay:     smoothingCoefficient(dynamicsDetectorRelease, samplesPerSec),
+		hold:      int(settings.Hold * float64(samplesPerSec)),
+		levels:    make([]float64, gains),
+		reduction: make([]float64, gains),
+		holding:   make([]int, gains),
+	}
+}
+
+// one pole smoothing reaching 1-1/e of a step after seconds
+func smoothingCoefficient(seconds float64, samplesPerSec uint32) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+
+	return math.Exp(-1 / (seconds * float64(samplesPerSec)))
+}
+
+func (dynamics *Dynamics) Latency() uint32 {
+	return 0
+}
+
+func (dynamics *Dynamics) Process(buffer [][]float64) {
+	dynamics.ProcessKeyed(buffer, buffer)
+}
+
+// process buffer with the gain driven by the level of key, a sidechain of the same length
+// unlinked, each channel follows the key channel of the same number, wrapping round fewer key channels
+// key may share slices with buffer
+func (dynamics *Dynamics) ProcessKeyed(buffer, key [][]float64) {
+	if len(key) == 0 {
+		return
+	}
+
+	makeup := DbToLinear(dynamics.settings.Makeup)
+	gains := make([]float64, len(dynamics.levels))
+
+	for n := range planarFrames(buffer) {
+		// detect every gain's level before any sample of the frame changes
+		for gain := range gains {
+			peak := 0.0
+			if dynamics.settings.Link {
+				for _, samples := range key {
+					peak = max(peak, math.Abs(samples[n]))
+				}
+			} else {
+				peak = math.Abs(key[gain%len(key)][n])
+			}
+
+			gains[gain] = dynamics.gain(gain, peak) * makeup
+		}
+
+		for channel, samples := range buffer {
+			samples[n] *= gains[channel%len(gains)]
+		}
+	}
+}
+
+// the linear gain for one detector after a new peak
+func (dynamics *Dynamics) gain(index int, peak float64) float64 {
+	dynamics.levels[index] = max(peak, dynamics.levels[index]*dynamics.decay)
+	target := dynamics.reductionAt(LinearToDb(dynamics.levels[index]))
+
+	// a compressor attacks by turning down, an expander or gate by opening up
+	current := dynamics.reduction[index]
+	closing := target > current
+	compressing := dynamics.settings.Type == DYNAMICS_COMPRESSOR
+
+	if closing && !compressing && dynamics.holding[index] > 0 {
+		dynamics.holding[index]--
+		return DbToLinear(-current)
+	} else if !closing {
+		dynamics.holding[index] = dynamics.hold
+	}
+
+	coefficient := dynamics.release
+	if closing == compressing {
+		coefficient = dynamics.attack
+	}
+
+	current = coefficient*current + (1-coefficient)*target
+	dynamics.reduction[index] = current
+
+	return DbToLinear(-current)
+}
+
+// the static curve, how many dB to turn down a level in dBFS
+func (dynamics *Dynamics) reductionAt(level float64) float64 {
+	settings := dynamics.settings
+	over := level - settings.Threshold
+	knee := max(settings.Knee, 0)
+
+	switch settings.Type {
+	case DYNAMICS_COMPRESSOR:
+		slope := 1 - 1/max(settings.Ratio, 1)
+		if 2*over <= -knee {
+			return 0
+		} else if 2*over < knee {
+			return slope * (over + knee/2) * (over + knee/2) / (2 * knee)
+		}
+
+		return slope * over
+
+	case DYNAMICS_EXPANDER, DYNAMICS_GATE:
+		var reduction float64
+		if settings.Type == DYNAMICS_GATE {
+			if over < 0 {
+				reduction = math.Inf(1)
+			}
+		} else {
+			slope := max(settings.Ratio, 1) - 1
+			if 2*over >= knee {
+				reduction = 0
+			} else if 2*over > -knee {
+				reduction = slope * (over - knee/2) * (over - knee/2) / (2 * knee)
+			} else {
+				reduction = -slope * over
+			}
+		}
+
+		if settings.Range > 0 {
+			reduction = min(reduction, settings.Range)
+		}
+
+		// fully closed is still finite so the smoothing can open it again
+		return min(reduction, 200)
+
+	default:
+		return 0
+	}
+}
+
+// a processor keyed from another source, fed a block at a time in step with the input
+type keyedDynamics struct {
+	dynamics *Dynamics
+	key      func(buffer [][]float64, start uint32) ([][]float64, error)
+	start    uint32
+	err      error
+}
+
+func (keyed *keyedDynamics) Process(buffer [][]float64) {
+	frames := planarFrames(buffer)
+
+	key, err := keyed.key(buffer, keyed.start)
+	if err != nil && keyed.err == nil {
+		keyed.err = err
+	}
+
+	keyed.dynamics.ProcessKeyed(buffer, key)
+	keyed.start += uint32(frames)
+}
+
+func (keyed *keyedDynamics) Latency() uint32 {
+	return 0
+}
+
+// run dynamics over a file in place, keyed from another file when key is not nil
+// the key is resampled to match, and treated as silent past its end
+func DynamicsFile(file *wave.WaveFile, settings DynamicsSettings, key *wave.WaveFile) error {
+	dynamics := NewDynamics(settings, file.Fmt.SamplesPerSec, file.Fmt.Channels)
+	if key == nil {
+		return ProcessFile(file, dynamics)
+	}
+
+	if key.Fmt.Channels == 0 {
+		return ErrKeyChannels
+	}
+
+	if key.Fmt.SamplesPerSec != file.Fmt.SamplesPerSec {
+		var err error
+		key, err = key.Resample(file.Fmt.SamplesPerSec)
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer := key.NewPlanarBuffer(blockFrames)
+	keyed := &keyedDynamics{
+		dynamics: dynamics,
+		key: func(block [][]float64, start uint32) ([][]float64, error) {
+			var read uint32
+			if start < key.Frames() {
+				var err error
+				read, err = key.ReadFrames(start, buffer)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			for _, samples := range buffer {
+				clear(samples[read:])
+			}
+
+			return sliceFrames(buffer, 0, uint32(planarFrames(block))), nil
+		},
+	}
+
+	err := ProcessFile(file, keyed)
+	if err != nil {
+		return err
+	}
+
+	return keyed.err
+}
+
+// run dynamics over a file in place, keyed from one of its own channels
+func DynamicsFileFromChannel(file *wave.WaveFile, settings DynamicsSettings, channel uint16) error {
+	if channel >= file.Fmt.Channels {
+		return wave.ErrChannelDoesNotExist
+	}
+
+	keyed := &keyedDynamics{
+		dynamics: NewDynamics(settings, file.Fmt.SamplesPerSec, file.Fmt.Channels),
+		key: func(block [][]float64, _ uint32) ([][]float64, error) {
+			// read from the block itself before it is changed
+			return block[channel : channel+1], nil
+		},
+	}
+
+	return ProcessFile(file, keyed)
+}
+
+// brickwall limit a file in place
+func LimitFile(file *wave.WaveFile, ceilingDb float64) error {
+	limiter := NewLimiter(ceilingDb, DEFAULT_LIMITER_LOOKAHEAD, DEFAULT_LIMITER_RELEASE, file.Fmt.SamplesPerSec, file.Fmt.Channels)
+	return ProcessFile(file, limiter)
+}
@@ -0,0 +1,205 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wave-edit/wave"
+)
+
+func TestDynamicsFileFromChannel(t *testing.T) {
+	settings := DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Attack: 0.001, Release: 0.05}
+
+	tests := []struct {
+		name    string
+		channel uint16
+		err     error
+	}{
+		{"first channel", 0, nil},
+		{"last channel", 1, nil},
+		{"past the last channel", 2, wave.ErrChannelDoesNotExist},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := sineFile(wave.PCM_16, 2, 48000, 1000, -6, 0.1)
+
+			err := DynamicsFileFromChannel(file, settings, test.channel)
+			if err != test.err {
+				t.Errorf("got error %v, want %v", err, test.err)
+			}
+		})
+	}
+}
+
+func TestDynamicsCurve(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings DynamicsSettings
+		level    float64
+		want     float64 // dB of reduction
+	}{
+		{"compressor below threshold", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4}, -30, 0},
+		{"compressor at threshold", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4}, -20, 0},
+		{"compressor above threshold", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4}, -10, 7.5},
+		{"compressor at full scale", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4}, 0, 15},
+		{"compressor at 2:1", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 2}, 0, 10},
+		{"compressor at 1:1", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 1}, 0, 0},
+		{"compressor below 1:1", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 0.5}, 0, 0},
+		{"knee start", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Knee: 10}, -25, 0},
+		{"knee middle", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Knee: 10}, -20, 0.9375},
+		{"knee quarter", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Knee: 10}, -17.5, 2.109375},
+		{"knee end", DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Knee: 10}, -15, 3.75},
+		{"expander above threshold", DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2}, -30, 0},
+		{"expander below threshold", DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2}, -50, 10},
+		{"expander range", DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2, Range: 6}, -50, 6},
+		{"expander knee middle", DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2, Knee: 10}, -40, 1.25},
+		{"expander knee end", DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2, Knee: 10}, -45, 5},
+		{"gate open", DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40}, -39, 0},
+		{"gate closed", DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40}, -41, 200},
+		{"gate range", DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40, Range: 20}, -41, 20},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dynamics := NewDynamics(test.settings, 1000, 1)
+			closeTo(t, "reduction", dynamics.reductionAt(test.level), test.want, 1e-9)
+		})
+	}
+}
+
+// a planar buffer holding value in every frame of each channel
+func constantBuffer(frames int, values ...float64) [][]float64 {
+	buffer := make([][]float64, len(values))
+	for channel, value := range values {
+		buffer[channel] = make([]float64, frames)
+		for n := range buffer[channel] {
+			buffer[channel][n] = value
+		}
+	}
+
+	return buffer
+}
+
+func TestCompressorAttack(t *testing.T) {
+	// 6dB under full scale is 14dB over the threshold, and turned down by 10.5dB
+	settings := DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Attack: 0.01, Makeup: 3}
+	loud := DbToLinear(-6)
+	buffer := constantBuffer(200, loud)
+	NewDynamics(settings, 1000, 1).Process(buffer)
+
+	gain := func(n int) float64 { return LinearToDb(buffer[0][n] / loud) }
+	closeTo(t, "after the attack time", gain(9), 3-10.5*(1-math.Exp(-1)), 1e-6)
+	closeTo(t, "settled", gain(199), 3-10.5, 1e-6)
+}
+
+func TestGateHoldAndRange(t *testing.T) {
+	// the first frame the gate turns a quiet tail down by its range
+	closedAt := func(hold float64) int {
+		settings := DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40, Hold: hold, Range: 20}
+		buffer := constantBuffer(300, 0.001)
+		for n := range 5 {
+			buffer[0][n] = 0.5
+		}
+
+		NewDynamics(settings, 1000, 1).Process(buffer)
+
+		for n, sample := range buffer[0] {
+			if n >= 5 && math.Abs(sample-0.0001) < 1e-12 {
+				return n
+			}
+		}
+
+		t.Fatalf("gate with %g seconds of hold never closed", hold)
+		return 0
+	}
+
+	if difference := closedAt(0.01) - closedAt(0); difference != 10 {
+		t.Errorf("hold kept the gate open for %d frames, want 10", difference)
+	}
+}
+
+func TestExpanderRange(t *testing.T) {
+	settings := DynamicsSettings{Type: DYNAMICS_EXPANDER, Threshold: -40, Ratio: 2, Range: 6}
+	buffer := constantBuffer(10, 0.001)
+	NewDynamics(settings, 1000, 1).Process(buffer)
+
+	closeTo(t, "gain", LinearToDb(buffer[0][9]/0.001), -6, 1e-9)
+}
+
+func TestDynamicsLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		link  bool
+		quiet float64 // dB of gain on the quiet channel
+	}{
+		{"unlinked", false, 0},
+		{"linked", true, -10.5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings := DynamicsSettings{Type: DYNAMICS_COMPRESSOR, Threshold: -20, Ratio: 4, Link: test.link}
+			loud := DbToLinear(-6)
+			buffer := constantBuffer(10, loud, 0.01)
+			NewDynamics(settings, 1000, 2).Process(buffer)
+
+			closeTo(t, "loud", LinearToDb(buffer[0][9]/loud), -10.5, 1e-9)
+			closeTo(t, "quiet", LinearToDb(buffer[1][9]/0.01), test.quiet, 1e-9)
+		})
+	}
+}
+
+func TestDynamicsFileWithKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyRate uint32
+	}{
+		{"same rate", 8000},
+		{"resampled", 4000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a steady file, and a key that is loud for its first tenth of a second then ends
+			file := wave.CreateWave(wave.PCM_FLOAT64, 1, 8000)
+			file.InsertSilence(0, 4000)
+			file.WriteFrames(0, constantBuffer(4000, 0.5))
+
+			key := wave.CreateWave(wave.PCM_FLOAT64, 1, test.keyRate)
+			key.InsertSilence(0, test.keyRate/10)
+			key.WriteFrames(0, constantBuffer(int(test.keyRate/10), 0.5))
+
+			settings := DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40}
+			err := DynamicsFile(file, settings, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			buffer := file.NewPlanarBuffer(file.Frames())
+			file.ReadFrames(0, buffer)
+
+			// open while the key plays, shut once its detector has fallen after the end
+			for n := range 1300 {
+				if math.Abs(buffer[0][n]-0.5) > 1e-9 {
+					t.Fatalf("frame %d: got %g while the key plays", n, buffer[0][n])
+				}
+			}
+
+			for n := 1600; n < 4000; n++ {
+				if math.Abs(buffer[0][n]) > 1e-9 {
+					t.Fatalf("frame %d: got %g after the key ends", n, buffer[0][n])
+				}
+			}
+		})
+	}
+}
+
+func TestDynamicsFileWithoutKeyChannels(t *testing.T) {
+	file := sineFile(wave.PCM_16, 1, 8000, 1000, -6, 0.1)
+	key := wave.CreateWave(wave.PCM_16, 0, 8000)
+
+	err := DynamicsFile(file, DynamicsSettings{Type: DYNAMICS_GATE, Threshold: -40}, key)
+	if err != ErrKeyChannels {
+		t.Errorf("got error %v, want %v", err, ErrKeyChannels)
+	}
+}
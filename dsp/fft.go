@@ -0,0 +1,67 @@
+package dsp
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// a radix 2 fast Fourier transform of one size
+type fft struct {
+	size     int
+	twiddles []complex128
+	reverse  []int
+}
+
+func newFFT(size int) *fft {
+	transform := &fft{
+		size:     size,
+		twiddles: make([]complex128, size/2),
+		reverse:  make([]int, size),
+	}
+
+	for n := range transform.twiddles {
+		transform.twiddles[n] = cmplx.Exp(complex(0, -2*math.Pi*float64(n)/float64(size)))
+	}
+
+	shift := bits.UintSize - bits.TrailingZeros(uint(size))
+	for n := range transform.reverse {
+		transform.reverse[n] = int(bits.Reverse(uint(n)) >> shift)
+	}
+
+	return transform
+}
+
+// transform data in place, size must be a power of 2, the inverse is scaled by 1/size
+func (transform *fft) transform(data []complex128, inverse bool) {
+	for n, reversed := range transform.reverse {
+		if n < reversed {
+			data[n], data[reversed] = data[reversed], data[n]
+		}
+	}
+
+	for length := 2; length <= transform.size; length *= 2 {
+		half := length / 2
+		step := transform.size / length
+
+		for start := 0; start < transform.size; start += length {
+			for n := range half {
+				twiddle := transform.twiddles[n*step]
+				if inverse {
+					twiddle = cmplx.Conj(twiddle)
+				}
+
+				even, odd := data[start+n], data[start+n+half]*twiddle
+				data[start+n] = even + odd
+				data[start+n+half] = even - odd
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(transform.size), 0)
+		for n := range data {
+			data[n] *= scale
+		}
+	}
+}
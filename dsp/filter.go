@@ -0,0 +1,148 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"wave-edit/wave"
+)
+
+// biquads run one after another for higher order filters
+type Cascade []Biquad
+
+// one band of a parametric equaliser
+type EqBand struct {
+	Type      FilterType
+	Frequency float64 // Hz
+	Q         float64
+	Gain      float64 // dB, for peaking and shelving bands
+}
+
+// a cascade run over every channel, each channel keeping its own state
+type Filter struct {
+	channels []Cascade
+}
+
+var ErrInvalidOrder = errors.New("filter order must be positive, and even for Linkwitz-Riley")
+var ErrNotPassFilter = errors.New("only low and high pass filters have an order")
+
+func (cascade Cascade) Process(samples []float64) {
+	for n := range cascade {
+		cascade[n].Process(samples)
+	}
+}
+
+func (cascade Cascade) ProcessSample(in float64) float64 {
+	for n := range cascade {
+		in = cascade[n].ProcessSample(in)
+	}
+
+	return in
+}
+
+func (cascade Cascade) Reset() {
+	for n := range cascade {
+		cascade[n].Reset()
+	}
+}
+
+// gain of the whole cascade at a frequency in dB
+func (cascade Cascade) Response(frequency float64, samplesPerSec uint32) float64 {
+	var gain float64
+	for n := range cascade {
+		gain += cascade[n].Response(frequency, samplesPerSec)
+	}
+
+	return gain
+}
+
+// a low or high pass Butterworth filter of any order, rolling off 6dB per octave per order
+func Butterworth(filterType FilterType, order int, frequency float64, samplesPerSec uint32) (Cascade, error) {
+	if filterType != FILTER_LOW_PASS && filterType != FILTER_HIGH_PASS {
+		return nil, ErrNotPassFilter
+	} else if order < 1 {
+		return nil, ErrInvalidOrder
+	}
+
+	cascade := Cascade{}
+
+	// each pair of poles becomes a biquad with its own Q, odd orders also have a real pole
+	for k := 1; k <= order/2; k++ {
+		angle := float64(2*k-1+order%2) * math.Pi / float64(2*order)
+		q := 1 / (2 * math.Cos(angle))
+		cascade = append(cascade, NewBiquad(filterType, frequency, q, 0, samplesPerSec))
+	}
+
+	if order%2 == 1 {
+		cascade = append(cascade, newFirstOrder(filterType, frequency, samplesPerSec))
+	}
+
+	return cascade, nil
+}
+
+// a Linkwitz-Riley crossover filter, two Butterworths of half the order
+// the low and high pass outputs at the same frequency sum flat, LR2 and LR6 high passes are
+// inverted for this as their bands are otherwise half a turn apart at the crossover
+func LinkwitzRiley(filterType FilterType, order int, frequency float64, samplesPerSec uint32) (Cascade, error) {
+	if order < 2 || order%2 != 0 {
+		return nil, ErrInvalidOrder
+	}
+
+	half, err := Butterworth(filterType, order/2, frequency, samplesPerSec)
+	if err != nil {
+		return nil, err
+	}
+
+	cascade := append(half, slices.Clone(half)...)
+	if filterType == FILTER_HIGH_PASS && order/2%2 == 1 {
+		cascade[0].B0, cascade[0].B1, cascade[0].B2 = -cascade[0].B0, -cascade[0].B1, -cascade[0].B2
+	}
+
+	return cascade, nil
+}
+
+// the cascade of biquads making up an equaliser
+func Equalizer(bands []EqBand, samplesPerSec uint32) Cascade {
+	cascade := make(Cascade, len(bands))
+	for n, band := range bands {
+		cascade[n] = NewBiquad(band.Type, band.Frequency, band.Q, band.Gain, samplesPerSec)
+	}
+
+	return cascade
+}
+
+// a processor running a copy of the cascade on each channel
+func NewFilter(cascade Cascade, channels uint16) *Filter {
+	filter := &Filter{channels: make([]Cascade, channels)}
+	for channel := range filter.channels {
+		filter.channels[channel] = slices.Clone(cascade)
+	}
+
+	return filter
+}
+
+func (filter *Filter) Process(buffer [][]float64) {
+	for channel, samples := range buffer {
+		filter.channels[channel].Process(samples)
+	}
+}
+
+func (filter *Filter) Latency() uint32 {
+	return 0
+}
+
+func (filter *Filter) Reset() {
+	for _, cascade := range filter.channels {
+		cascade.Reset()
+	}
+}
+
+// filter every channel of a file in place
+func FilterFile(file *wave.WaveFile, cascade Cascade) error {
+	return ProcessFile(file, NewFilter(cascade, file.Fmt.Channels))
+}
+
+// equalise every channel of a file in place, bands designed for its sample rate
+func EqualizeFile(file *wave.WaveFile, bands []EqBand) error {
+	return FilterFile(file, Equalizer(bands, file.Fmt.SamplesPerSec))
+}
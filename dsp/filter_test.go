@@ -0,0 +1,126 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// gain of a sine through the filter once it settles, from the power over the last second
+func measuredGain(process func(float64) float64, frequency float64, samplesPerSec uint32) float64 {
+	var power float64
+	for n := range 2 * int(samplesPerSec) {
+		out := process(math.Sin(2 * math.Pi * frequency * float64(n) / float64(samplesPerSec)))
+		if n >= int(samplesPerSec) {
+			power += out * out
+		}
+	}
+
+	return LinearToDb(math.Sqrt(2 * power / float64(samplesPerSec)))
+}
+
+func TestButterworth(t *testing.T) {
+	for order := 1; order <= 8; order++ {
+		for _, filterType := range []FilterType{FILTER_LOW_PASS, FILTER_HIGH_PASS} {
+			cascade, err := Butterworth(filterType, order, 1000, 48000)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the analogue response, with frequencies warped by the bilinear transform
+			for _, frequency := range []float64{62.5, 500, 1000, 2000, 16000} {
+				ratio := math.Tan(math.Pi*frequency/48000) / math.Tan(math.Pi*1000/48000)
+				if filterType == FILTER_HIGH_PASS {
+					ratio = 1 / ratio
+				}
+
+				want := -10 * math.Log10(1+math.Pow(ratio, 2*float64(order)))
+				closeTo(t, "response", cascade.Response(frequency, 48000), want, 1e-6)
+			}
+
+			closeTo(t, "cutoff", cascade.Response(1000, 48000), -3.0103, 0.001)
+		}
+	}
+}
+
+func TestLinkwitzRiley(t *testing.T) {
+	for _, order := range []int{2, 4, 6, 8} {
+		low, err := LinkwitzRiley(FILTER_LOW_PASS, order, 1000, 48000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		high, err := LinkwitzRiley(FILTER_HIGH_PASS, order, 1000, 48000)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		closeTo(t, "low pass at the crossover", low.Response(1000, 48000), -6.0206, 0.001)
+		closeTo(t, "high pass at the crossover", high.Response(1000, 48000), -6.0206, 0.001)
+
+		// the bands sum to an all pass
+		for _, frequency := range []float64{100, 700, 1000, 1400, 10000} {
+			low.Reset()
+			high.Reset()
+
+			sum := measuredGain(func(in float64) float64 {
+				return low.ProcessSample(in) + high.ProcessSample(in)
+			}, frequency, 48000)
+
+			closeTo(t, "sum", sum, 0, 0.01)
+		}
+	}
+
+	for _, order := range []int{0, 3, -2} {
+		_, err := LinkwitzRiley(FILTER_LOW_PASS, order, 1000, 48000)
+		if err != ErrInvalidOrder {
+			t.Errorf("order %d: got error %v, want %v", order, err, ErrInvalidOrder)
+		}
+	}
+}
+
+func TestBiquadResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		biquad    Biquad
+		frequency float64
+		want      float64 // dB
+	}{
+		{"peaking at the centre", NewBiquad(FILTER_PEAKING, 1000, 1, 6, 48000), 1000, 6},
+		{"peaking cut at the centre", NewBiquad(FILTER_PEAKING, 1000, 2, -9, 48000), 1000, -9},
+		{"peaking far away", NewBiquad(FILTER_PEAKING, 1000, 1, 6, 48000), 20, 0},
+		{"band pass at the centre", NewBiquad(FILTER_BAND_PASS, 2000, 4, 0, 48000), 2000, 0},
+		{"notch far away", NewBiquad(FILTER_NOTCH, 2000, 4, 0, 48000), 200, 0},
+		{"low shelf", NewBiquad(FILTER_LOW_SHELF, 200, BUTTERWORTH_Q, 6, 48000), 10, 6},
+		{"low shelf at the corner", NewBiquad(FILTER_LOW_SHELF, 200, BUTTERWORTH_Q, 6, 48000), 200, 3},
+		{"high shelf", NewBiquad(FILTER_HIGH_SHELF, 5000, BUTTERWORTH_Q, -4, 48000), 20000, -4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			closeTo(t, "response", test.biquad.Response(test.frequency, 48000), test.want, 0.05)
+
+			// the response matches what the filter does to a sine
+			measured := measuredGain(test.biquad.ProcessSample, test.frequency, 48000)
+			closeTo(t, "measured", measured, test.want, 0.05)
+		})
+	}
+}
+
+func TestEqualizer(t *testing.T) {
+	bands := []EqBand{
+		{FILTER_LOW_SHELF, 100, BUTTERWORTH_Q, 3},
+		{FILTER_PEAKING, 1000, 1, -6},
+		{FILTER_HIGH_SHELF, 8000, BUTTERWORTH_Q, 2},
+	}
+
+	cascade := Equalizer(bands, 48000)
+
+	var want float64
+	for _, band := range bands {
+		biquad := NewBiquad(band.Type, band.Frequency, band.Q, band.Gain, 48000)
+		want += biquad.Response(3000, 48000)
+	}
+
+	closeTo(t, "response", cascade.Response(3000, 48000), want, 1e-9)
+	closeTo(t, "measured", measuredGain(cascade.ProcessSample, 3000, 48000), want, 0.05)
+}
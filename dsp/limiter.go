@@ -0,0 +1,97 @@
+package dsp
+
+import "math"
+
+const DEFAULT_LIMITER_LOOKAHEAD = 0.005 // seconds
+const DEFAULT_LIMITER_RELEASE = 0.05    // seconds
+
+// a lookahead brickwall limiter, linked across channels so the stereo image holds
+type Limiter struct {
+	Ceiling float64 // Largest absolute sample value let through, linear
+
+	window  int     // lookahead in frames, the output is delayed one less than this
+	release float64 // per frame recovery coefficient
+	delay   [][]float64
+	next    int
+	minimum []gainTime // gains wanted over the lookahead, increasing
+	held    float64    // gain after release smoothing
+	average []float64  // held gains over the lookahead, averaged to smooth the attack
+	sum     float64
+	frame   int
+}
+
+type gainTime struct {
+	gain  float64
+	frame int
+}
+
+func NewLimiter(ceilingDb float64, lookahead, release float64, samplesPerSec uint32, channels uint16) *Limiter {
+	window := max(1, int(math.Round(lookahead*float64(samplesPerSec))))
+
+	limiter := &Limiter{
+		Ceiling: DbToLinear(ceilingDb),
+		window:  window,
+		release: math.Exp(-1 / (max(release, 1e-6) * float64(samplesPerSec))),
+		delay:   make([][]float64, channels),
+		held:    1,
+		average: make([]float64, window),
+		sum:     float64(window),
+	}
+
+	for channel := range limiter.delay {
+		limiter.delay[channel] = make([]float64, window)
+	}
+
+	for n := range limiter.average {
+		limiter.average[n] = 1
+	}
+
+	return limiter
+}
+
+func (limiter *Limiter) Latency() uint32 {
+	return uint32(limiter.window - 1)
+}
+
+func (limiter *Limiter) Process(buffer [][]float64) {
+	for n := range planarFrames(buffer) {
+		peak := 0.0
+		for _, samples := range buffer {
+			peak = max(peak, math.Abs(samples[n]))
+		}
+
+		wanted := 1.0
+		if peak > limiter.Ceiling {
+			wanted = limiter.Ceiling / peak
+		}
+
+		// smallest gain wanted by any frame still in the lookahead
+		for len(limiter.minimum) > 0 && limiter.minimum[len(limiter.minimum)-1].gain >= wanted {
+			limiter.minimum = limiter.minimum[:len(limiter.minimum)-1]
+		}
+		limiter.minimum = append(limiter.minimum, gainTime{wanted, limiter.frame})
+		if limiter.minimum[0].frame <= limiter.frame-limiter.window {
+			limiter.minimum = limiter.minimum[1:]
+		}
+
+		limiter.held = min(limiter.minimum[0].gain, 1-(1-limiter.held)*limiter.release)
+
+		// averaging over the lookahead never rises above the gain the delayed frame needs
+		slot := limiter.frame % limiter.window
+		limiter.sum += limiter.held - limiter.average[slot]
+		limiter.average[slot] = limiter.held
+		gain := min(1, limiter.sum/float64(limiter.window))
+
+		oldest := (limiter.next + 1) % limiter.window
+		for channel, samples := range buffer {
+			limiter.delay[channel][limiter.next] = samples[n]
+			delayed := limiter.delay[channel][oldest]
+
+			// the running sum can drift by a rounding error
+			samples[n] = max(-limiter.Ceiling, min(limiter.Ceiling, delayed*gain))
+		}
+
+		limiter.next = oldest
+		limiter.frame++
+	}
+}
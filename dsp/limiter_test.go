@@ -0,0 +1,129 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wave-edit/wave"
+)
+
+func TestLimiterCeiling(t *testing.T) {
+	tests := []struct {
+		name      string
+		ceiling   float64
+		lookahead float64
+		release   float64
+		block     int
+	}{
+		{"default", -1, DEFAULT_LIMITER_LOOKAHEAD, DEFAULT_LIMITER_RELEASE, 512},
+		{"single frames", -1, DEFAULT_LIMITER_LOOKAHEAD, DEFAULT_LIMITER_RELEASE, 1},
+		{"no lookahead", -3, 0, DEFAULT_LIMITER_RELEASE, 100},
+		{"fast release", -0.1, 0.001, 0.001, 333},
+		{"low ceiling", -20, DEFAULT_LIMITER_LOOKAHEAD, 0.2, 4096},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// noise up to 12dB over full scale, louder in one channel
+			left, right := noise(48000, 1), noise(48000, 2)
+			for n := range left {
+				left[n] *= 4
+			}
+
+			limiter := NewLimiter(test.ceiling, test.lookahead, test.release, 48000, 2)
+			ceiling := DbToLinear(test.ceiling)
+
+			for start := 0; start < len(left); start += test.block {
+				end := min(len(left), start+test.block)
+				buffer := [][]float64{left[start:end], right[start:end]}
+				limiter.Process(buffer)
+
+				for channel, samples := range buffer {
+					for n, sample := range samples {
+						if math.Abs(sample) > ceiling {
+							t.Fatalf("channel %d frame %d: %f over the ceiling %f", channel, start+n, sample, ceiling)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterPassesQuietAudio(t *testing.T) {
+	input := noise(4800, 3)
+	for n := range input {
+		input[n] *= 0.5
+	}
+
+	limiter := NewLimiter(-1, DEFAULT_LIMITER_LOOKAHEAD, DEFAULT_LIMITER_RELEASE, 48000, 1)
+	output := [][]float64{append([]float64(nil), input...)}
+	limiter.Process(output)
+
+	latency := int(limiter.Latency())
+	if latency != 239 {
+		t.Errorf("latency: got %d, want 239", latency)
+	}
+
+	for n := latency; n < len(input); n++ {
+		if output[0][n] != input[n-latency] {
+			t.Fatalf("frame %d: got %f, want %f", n, output[0][n], input[n-latency])
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        wave.WaveFormat
+		normalization Normalization
+		err           error
+	}{
+		{"peak", wave.PCM_16, Normalization{Mode: NORMALIZE_PEAK, Target: -1}, nil},
+		{"peak to full scale", wave.PCM_24, Normalization{Mode: NORMALIZE_PEAK, Target: 0}, nil},
+		{"rms", wave.PCM_FLOAT32, Normalization{Mode: NORMALIZE_RMS, Target: -20}, nil},
+		{"loudness", wave.PCM_FLOAT32, Normalization{Mode: NORMALIZE_LOUDNESS, Target: -16}, nil},
+		{"too loud for pcm", wave.PCM_16, Normalization{Mode: NORMALIZE_RMS, Target: 0}, ErrNormalizeClips},
+		{"limited", wave.PCM_16, Normalization{Mode: NORMALIZE_RMS, Target: 0, Limit: true, Ceiling: -1}, nil},
+		{"unknown mode", wave.PCM_16, Normalization{Mode: 99}, ErrUnknownNormalizeMode},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := sineFile(test.format, 2, 48000, 1000, -20, 5)
+
+			_, err := Normalize(file, test.normalization)
+			if err != test.err {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			} else if err != nil {
+				return
+			}
+
+			if test.normalization.Limit {
+				peak, err := MeasurePeak(file)
+				if err != nil {
+					t.Fatal(err)
+				} else if peak > test.normalization.Ceiling+1e-9 {
+					t.Errorf("peak %f over the ceiling %f", peak, test.normalization.Ceiling)
+				}
+				return
+			}
+
+			level, err := MeasureLevel(file, test.normalization.Mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// pcm rounds each sample
+			closeTo(t, "level", level, test.normalization.Target, 0.01)
+		})
+	}
+}
+
+func TestNormalizeSilence(t *testing.T) {
+	file := sineFile(wave.PCM_16, 1, 48000, 1000, math.Inf(-1), 1)
+
+	_, err := Normalize(file, Normalization{Mode: NORMALIZE_PEAK})
+	if err != ErrSilent {
+		t.Errorf("got error %v, want %v", err, ErrSilent)
+	}
+}
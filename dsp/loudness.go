@@ -0,0 +1,222 @@
+package dsp
+
+import (
+	"math"
+	"slices"
+	"wave-edit/wave"
+)
+
+type Loudness struct {
+	Integrated   float64   // Gated loudness of the whole file in LUFS
+	MaxMomentary float64   // Loudest 400ms window in LUFS
+	MaxShortTerm float64   // Loudest 3s window in LUFS
+	Range        float64   // Loudness range in LU, per EBU Tech 3342
+	TruePeak     float64   // Largest true peak of any channel in dBTP
+	ChannelPeaks []float64 // True peak of each channel in dBTP
+}
+
+// loudness is measured over 100ms blocks, windows are made of several
+const loudnessBlockSeconds = 0.1
+const momentaryBlocks = 4
+const shortTermBlocks = 30
+
+const absoluteGate = -70.0
+const integratedRelativeGate = -10.0
+const rangeRelativeGate = -20.0
+
+// K-weighting from ITU-R BS.1770, designed for any sample rate
+func kWeighting(samplesPerSec uint32) Cascade {
+	rate := float64(samplesPerSec)
+
+	// high shelf modelling the head
+	k := math.Tan(math.Pi * 1681.974450955533 / rate)
+	q := 0.7071752369554196
+	vh := math.Pow(10, 3.999843853973347/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+
+	shelf := Biquad{
+		B0: (vh + vb*k/q + k*k) / a0,
+		B1: 2 * (k*k - vh) / a0,
+		B2: (vh - vb*k/q + k*k) / a0,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/q + k*k) / a0,
+	}
+
+	// RLB high pass
+	k = math.Tan(math.Pi * 38.13547087602444 / rate)
+	q = 0.5003270373238773
+	a0 = 1 + k/q + k*k
+
+	highPass := Biquad{
+		B0: 1,
+		B1: -2,
+		B2: 1,
+		A1: 2 * (k*k - 1) / a0,
+		A2: (1 - k/q + k*k) / a0,
+	}
+
+	return Cascade{shelf, highPass}
+}
+
+// weight of each channel when summing, surrounds are louder and LFE is left out
+func channelWeights(fmt *wave.FmtChunk) []float64 {
+	weights := make([]float64, fmt.Channels)
+
+	for n, speaker := range fmt.Speakers() {
+		switch speaker {
+		case wave.SPEAKER_LOW_FREQUENCY:
+			weights[n] = 0
+		case wave.SPEAKER_BACK_LEFT, wave.SPEAKER_BACK_RIGHT, wave.SPEAKER_SIDE_LEFT, wave.SPEAKER_SIDE_RIGHT:
+			weights[n] = 1.41
+		default:
+			weights[n] = 1
+		}
+	}
+
+	return weights
+}
+
+// measure loudness per ITU-R BS.1770-4 and EBU R128
+func MeasureLoudness(file *wave.WaveFile) (*Loudness, error) {
+	gatingFrames := max(1, uint32(math.Round(float64(file.Fmt.SamplesPerSec)*loudnessBlockSeconds)))
+	weights := channelWeights(file.Fmt)
+
+	filter := NewFilter(kWeighting(file.Fmt.SamplesPerSec), file.Fmt.Channels)
+	peaks := make([]TruePeakMeter, file.Fmt.Channels)
+
+	// weighted mean square of each 100ms block
+	blocks := []float64{}
+	var blockPower float64
+	var blockFilled uint32
+
+	err := readBlocks(file, func(buffer [][]float64) {
+		for channel, samples := range buffer {
+			peaks[channel].Process(samples)
+		}
+		filter.Process(buffer)
+
+		for n := range planarFrames(buffer) {
+			for channel, samples := range buffer {
+				blockPower += weights[channel] * samples[n] * samples[n]
+			}
+
+			blockFilled++
+			if blockFilled == gatingFrames {
+				blocks = append(blocks, blockPower/float64(gatingFrames))
+				blockPower, blockFilled = 0, 0
+			}
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	momentary := windowPowers(blocks, momentaryBlocks)
+	shortTerm := windowPowers(blocks, shortTermBlocks)
+
+	loudness := &Loudness{
+		Integrated:   gatedLoudness(momentary, integratedRelativeGate),
+		MaxMomentary: powerToLufs(maxOrZero(momentary)),
+		MaxShortTerm: powerToLufs(maxOrZero(shortTerm)),
+		Range:        loudnessRange(shortTerm),
+		TruePeak:     math.Inf(-1),
+		ChannelPeaks: make([]float64, len(peaks)),
+	}
+
+	for channel := range peaks {
+		loudness.ChannelPeaks[channel] = peaks[channel].PeakDb()
+		loudness.TruePeak = max(loudness.TruePeak, loudness.ChannelPeaks[channel])
+	}
+
+	return loudness, nil
+}
+
+// mean power of each window of length blocks, stepping one block at a time
+func windowPowers(blocks []float64, length int) []float64 {
+	if len(blocks) < length {
+		return nil
+	}
+
+	windows := make([]float64, len(blocks)-length+1)
+	var sum float64
+
+	for n, power := range blocks {
+		sum += power
+		if n >= length {
+			sum -= blocks[n-length]
+		}
+
+		if n >= length-1 {
+			windows[n-length+1] = sum / float64(length)
+		}
+	}
+
+	return windows
+}
+
+func powerToLufs(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+func lufsToPower(lufs float64) float64 {
+	return math.Pow(10, (lufs+0.691)/10)
+}
+
+// windows above the absolute gate, and above a gate relative to their mean
+func gatedPowers(windows []float64, relativeGate float64) []float64 {
+	absoluteGated := slices.DeleteFunc(slices.Clone(windows), func(power float64) bool {
+		return powerToLufs(power) <= absoluteGate
+	})
+
+	if len(absoluteGated) == 0 {
+		return nil
+	}
+
+	threshold := powerToLufs(mean(absoluteGated)) + relativeGate
+
+	return slices.DeleteFunc(absoluteGated, func(power float64) bool {
+		return powerToLufs(power) <= threshold
+	})
+}
+
+func gatedLoudness(windows []float64, relativeGate float64) float64 {
+	gated := gatedPowers(windows, relativeGate)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+
+	return powerToLufs(mean(gated))
+}
+
+// spread between the 10th and 95th percentile of gated short-term loudness
+func loudnessRange(shortTerm []float64) float64 {
+	gated := gatedPowers(shortTerm, rangeRelativeGate)
+	if len(gated) == 0 {
+		return 0
+	}
+
+	slices.Sort(gated)
+	low := gated[int(math.Round(0.10*float64(len(gated)-1)))]
+	high := gated[int(math.Round(0.95*float64(len(gated)-1)))]
+
+	return powerToLufs(high) - powerToLufs(low)
+}
+
+func mean(values []float64) float64 {
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+
+	return sum / float64(len(values))
+}
+
+func maxOrZero(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	return slices.Max(values)
+}
@@ -0,0 +1,99 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wave-edit/wave"
+)
+
+// cases from EBU Tech 3341 and 3342, with their tolerances
+func TestLoudnessKnownAnswers(t *testing.T) {
+	tests := []struct {
+		name       string
+		rate       uint32
+		levels     []float64 // dBFS of each part of the 1kHz tone
+		seconds    []float64
+		integrated float64
+		lra        float64 // NaN to skip
+	}{
+		{"3341 case 1", 48000, []float64{-23}, []float64{20}, -23, math.NaN()},
+		{"3341 case 2", 48000, []float64{-33}, []float64{20}, -33, math.NaN()},
+		{"3341 case 3", 48000, []float64{-36, -23, -36}, []float64{10, 60, 10}, -23, math.NaN()},
+		{"3341 case 4", 48000, []float64{-72, -36, -23, -36, -72}, []float64{10, 10, 60, 10, 10}, -23, math.NaN()},
+		{"3342 case 1", 48000, []float64{-20, -30}, []float64{20, 20}, -22.6, 10},
+		{"3342 case 2", 48000, []float64{-20, -15}, []float64{20, 20}, -16.4, 5},
+		{"44.1kHz", 44100, []float64{-23}, []float64{20}, -23, math.NaN()},
+		{"96kHz", 96000, []float64{-23}, []float64{10}, -23, math.NaN()},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var total float64
+			for _, seconds := range test.seconds {
+				total += seconds
+			}
+
+			file := sineFile(wave.PCM_FLOAT32, 2, test.rate, 1000, test.levels[0], total)
+			var start float64
+			for n, level := range test.levels {
+				appendSine(file, uint32(start*float64(test.rate)), 1000, level, 0)
+				start += test.seconds[n]
+			}
+
+			loudness, err := MeasureLoudness(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// integrated loudness has a tolerance of 0.1 LU, except 3342 which only checks the range
+			tolerance := 0.1
+			if !math.IsNaN(test.lra) {
+				tolerance = 0.5
+				closeTo(t, "loudness range", loudness.Range, test.lra, 1)
+			}
+
+			closeTo(t, "integrated", loudness.Integrated, test.integrated, tolerance)
+		})
+	}
+}
+
+func TestMomentaryAndShortTerm(t *testing.T) {
+	file := sineFile(wave.PCM_FLOAT32, 2, 48000, 1000, -23, 5)
+
+	loudness, err := MeasureLoudness(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closeTo(t, "max momentary", loudness.MaxMomentary, -23, 0.1)
+	closeTo(t, "max short term", loudness.MaxShortTerm, -23, 0.1)
+}
+
+func TestTruePeak(t *testing.T) {
+	tests := []struct {
+		name      string
+		frequency float64
+		phase     float64
+		want      float64 // dBTP
+	}{
+		// a quarter of the sample rate at 45 degrees puts every sample 3dB below the peak
+		{"between samples", 12000, math.Pi / 4, 0},
+		{"on samples", 12000, 0, 0},
+		{"1kHz", 1000, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := sineFile(wave.PCM_FLOAT32, 1, 48000, test.frequency, 0, 1)
+			appendSine(file, 0, test.frequency, -6, test.phase)
+
+			loudness, err := MeasureLoudness(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// EBU Tech 3341 allows +0.2 and -0.4 dB
+			closeTo(t, "true peak", loudness.TruePeak, test.want-6, 0.4)
+		})
+	}
+}
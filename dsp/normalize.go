@@ -0,0 +1,124 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"wave-edit/wave"
+)
+
+type NormalizeMode int
+
+const (
+	NORMALIZE_PEAK      NormalizeMode = iota // Target in dBFS
+	NORMALIZE_TRUE_PEAK                      // Target in dBTP
+	NORMALIZE_RMS                            // Target in dBFS
+	NORMALIZE_LOUDNESS                       // Target in LUFS
+)
+
+type Normalization struct {
+	Mode    NormalizeMode
+	Target  float64
+	Limit   bool    // Limit peaks to Ceiling rather than letting them clip
+	Ceiling float64 // dBFS
+}
+
+var ErrUnknownNormalizeMode = errors.New("unknown normalisation mode")
+var ErrSilent = errors.New("file is silent")
+var ErrNormalizeClips = errors.New("normalised file would clip, use a limiter or a lower target")
+
+// the level of a whole file as measured by a normalisation mode
+func MeasureLevel(file *wave.WaveFile, mode NormalizeMode) (float64, error) {
+	switch mode {
+	case NORMALIZE_PEAK:
+		return MeasurePeak(file)
+
+	case NORMALIZE_TRUE_PEAK:
+		meters := make([]TruePeakMeter, file.Fmt.Channels)
+		err := readBlocks(file, func(buffer [][]float64) {
+			for channel, samples := range buffer {
+				meters[channel].Process(samples)
+			}
+		})
+
+		peak := 0.0
+		for _, meter := range meters {
+			peak = max(peak, meter.Peak)
+		}
+
+		return LinearToDb(peak), err
+
+	case NORMALIZE_RMS:
+		return MeasureRms(file)
+
+	case NORMALIZE_LOUDNESS:
+		loudness, err := MeasureLoudness(file)
+		if err != nil {
+			return 0, err
+		}
+
+		return loudness.Integrated, nil
+
+	default:
+		return 0, ErrUnknownNormalizeMode
+	}
+}
+
+// the largest absolute sample value of any channel in dBFS
+func MeasurePeak(file *wave.WaveFile) (float64, error) {
+	peak := 0.0
+	err := readBlocks(file, func(buffer [][]float64) {
+		for _, samples := range buffer {
+			for _, sample := range samples {
+				peak = max(peak, math.Abs(sample))
+			}
+		}
+	})
+
+	return LinearToDb(peak), err
+}
+
+// the RMS level of all channels together in dBFS
+func MeasureRms(file *wave.WaveFile) (float64, error) {
+	var sum float64
+	err := readBlocks(file, func(buffer [][]float64) {
+		for _, samples := range buffer {
+			for _, sample := range samples {
+				sum += sample * sample
+			}
+		}
+	})
+
+	count := float64(file.Frames()) * float64(file.Fmt.Channels)
+	return LinearToDb(math.Sqrt(sum / count)), err
+}
+
+// change the gain of a file so it measures at the target level, returns the gain in dB
+// integer formats would clip past full scale, so that is an error unless limiting
+func Normalize(file *wave.WaveFile, normalization Normalization) (float64, error) {
+	level, err := MeasureLevel(file, normalization.Mode)
+	if err != nil {
+		return 0, err
+	} else if math.IsInf(level, -1) || math.IsNaN(level) {
+		return 0, ErrSilent
+	}
+
+	gainDb := normalization.Target - level
+	chain := Chain{Gain(DbToLinear(gainDb))}
+
+	if normalization.Limit {
+		chain = append(chain, NewLimiter(normalization.Ceiling, DEFAULT_LIMITER_LOOKAHEAD, DEFAULT_LIMITER_RELEASE,
+			file.Fmt.SamplesPerSec, file.Fmt.Channels))
+	} else if !file.Fmt.Format.IsFloat() {
+		peak, err := MeasurePeak(file)
+		if err != nil {
+			return 0, err
+		}
+
+		// allow for rounding when normalising the peak to full scale
+		if peak+gainDb > 1e-9 {
+			return 0, ErrNormalizeClips
+		}
+	}
+
+	return gainDb, ProcessFile(file, chain)
+}
@@ -0,0 +1,124 @@
+package dsp
+
+import "wave-edit/wave"
+
+// frames decoded at once while working through a file
+const blockFrames = 1 << 16
+
+// processes planar audio in place, a block at a time, keeping state between blocks
+type Processor interface {
+	Process(buffer [][]float64)
+	Latency() uint32 // Frames the output is delayed behind the input
+}
+
+// processors run one after another
+type Chain []Processor
+
+// a fixed linear gain
+type Gain float64
+
+func (chain Chain) Process(buffer [][]float64) {
+	for _, processor := range chain {
+		processor.Process(buffer)
+	}
+}
+
+func (chain Chain) Latency() uint32 {
+	var latency uint32
+	for _, processor := range chain {
+		latency += processor.Latency()
+	}
+
+	return latency
+}
+
+func (gain Gain) Process(buffer [][]float64) {
+	for _, samples := range buffer {
+		for n := range samples {
+			samples[n] *= float64(gain)
+		}
+	}
+}
+
+func (gain Gain) Latency() uint32 {
+	return 0
+}
+
+// call handle with every frame of a file, a block at a time
+func readBlocks(file *wave.WaveFile, handle func(buffer [][]float64)) error {
+	buffer := file.NewPlanarBuffer(min(blockFrames, file.Frames()))
+
+	for start := uint32(0); start < file.Frames(); start += blockFrames {
+		frames, err := file.ReadFrames(start, buffer)
+		if err != nil {
+			return err
+		}
+
+		handle(sliceFrames(buffer, 0, frames))
+	}
+
+	return nil
+}
+
+// run a processor over a whole file in place, lining the output up with the input
+func ProcessFile(file *wave.WaveFile, processor Processor) error {
+	frames := file.Frames()
+	latency := processor.Latency()
+	buffer := file.NewPlanarBuffer(blockFrames)
+
+	// keep going past the end to flush out the delayed frames
+	for start := uint32(0); start < frames+latency; start += blockFrames {
+		length := min(blockFrames, frames+latency-start)
+
+		var read uint32
+		if start < frames {
+			var err error
+			read, err = file.ReadFrames(start, buffer)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, samples := range buffer {
+			clear(samples[read:])
+		}
+
+		block := sliceFrames(buffer, 0, length)
+		processor.Process(block)
+
+		// output frames from before the start of the file are only delay
+		if start+length <= latency {
+			continue
+		}
+
+		skip := latency - min(latency, start)
+		outStart := start + skip - latency
+
+		end := min(length, skip+frames-outStart)
+		err := file.WriteFrames(outStart, sliceFrames(block, skip, end))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// the number of frames in a planar buffer
+func planarFrames(buffer [][]float64) int {
+	if len(buffer) == 0 {
+		return 0
+	}
+
+	return len(buffer[0])
+}
+
+// the frames from start to end of every channel
+func sliceFrames(buffer [][]float64, start, end uint32) [][]float64 {
+	sliced := make([][]float64, len(buffer))
+	for channel, samples := range buffer {
+		sliced[channel] = samples[start:end]
+	}
+
+	return sliced
+}
@@ -0,0 +1,157 @@
+package dsp
+
+import "math"
+
+// Freeverb tuning by Jezar at Dreampoint, delays in samples at 44.1kHz
+var reverbCombDelays = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+var reverbAllpassDelays = []int{556, 441, 341, 225}
+
+const reverbTuningRate = 44100
+const reverbStereoSpread = 23
+const reverbInputGain = 0.015
+const reverbAllpassFeedback = 0.5
+
+type ReverbSettings struct {
+	RoomSize float64 // 0 to 1, how long the reverb rings
+	Damping  float64 // 0 to 1, how quickly high frequencies die away
+	Wet      float64 // Linear gain of the reverb
+	Dry      float64 // Linear gain of the original signal
+	Width    float64 // 0 to 1, stereo spread of the reverb
+}
+
+// a lowpass feedback comb filter
+type combFilter struct {
+	buffer []float64
+	next   int
+	store  float64
+}
+
+type allpassFilter struct {
+	buffer []float64
+	next   int
+}
+
+// the filters of one output channel
+type reverbTank struct {
+	combs     []combFilter
+	allpasses []allpassFilter
+}
+
+// a Freeverb style reverb, a bank of parallel combs into a chain of allpasses for each channel
+type Reverb struct {
+	tanks    []reverbTank
+	feedback float64
+	damping  float64
+	wet1     float64
+	wet2     float64
+	dry      float64
+	outputs  []float64
+}
+
+func NewReverb(settings ReverbSettings, samplesPerSec uint32, channels uint16) *Reverb {
+	scale := float64(samplesPerSec) / reverbTuningRate
+	delay := func(samples, channel int) int {
+		return max(1, int(math.Round(float64(samples+channel*reverbStereoSpread)*scale)))
+	}
+
+	reverb := &Reverb{
+		tanks:    make([]reverbTank, channels),
+		feedback: reverbFeedback(settings.RoomSize),
+		damping:  max(0, min(settings.Damping, 1)) * 0.4,
+		dry:      settings.Dry,
+		outputs:  make([]float64, channels),
+	}
+
+	// width only means something between a pair of channels
+	reverb.wet1 = settings.Wet
+	if channels == 2 {
+		width := max(0, min(settings.Width, 1))
+		reverb.wet1 = settings.Wet * (width/2 + 0.5)
+		reverb.wet2 = settings.Wet * (1 - width) / 2
+	}
+
+	for channel := range reverb.tanks {
+		tank := &reverb.tanks[channel]
+
+		for _, samples := range reverbCombDelays {
+			tank.combs = append(tank.combs, combFilter{buffer: make([]float64, delay(samples, channel))})
+		}
+
+		for _, samples := range reverbAllpassDelays {
+			tank.allpasses = append(tank.allpasses, allpassFilter{buffer: make([]float64, delay(samples, channel))})
+		}
+	}
+
+	return reverb
+}
+
+func reverbFeedback(roomSize float64) float64 {
+	return max(0, min(roomSize, 1))*0.28 + 0.7
+}
+
+// seconds for the reverb to fall by 60dB, ignoring damping
+func ReverbTail(settings ReverbSettings) float64 {
+	longest := float64(reverbCombDelays[len(reverbCombDelays)-1]) / reverbTuningRate
+	return 3 * longest / -math.Log10(reverbFeedback(settings.RoomSize))
+}
+
+func (reverb *Reverb) Latency() uint32 {
+	return 0
+}
+
+func (reverb *Reverb) Process(buffer [][]float64) {
+	for n := range planarFrames(buffer) {
+		// every channel feeds the same mono input into the tanks
+		var input float64
+		for _, samples := range buffer {
+			input += samples[n]
+		}
+		input *= reverbInputGain
+
+		for channel := range reverb.tanks {
+			reverb.outputs[channel] = reverb.tanks[channel].process(input, reverb.feedback, reverb.damping)
+		}
+
+		for channel, samples := range buffer {
+			wet := reverb.wet1 * reverb.outputs[channel]
+			if len(buffer) == 2 {
+				wet += reverb.wet2 * reverb.outputs[1-channel]
+			}
+
+			samples[n] = samples[n]*reverb.dry + wet
+		}
+	}
+}
+
+func (tank *reverbTank) process(input, feedback, damping float64) float64 {
+	var output float64
+	for n := range tank.combs {
+		output += tank.combs[n].process(input, feedback, damping)
+	}
+
+	for n := range tank.allpasses {
+		output = tank.allpasses[n].process(output)
+	}
+
+	return output
+}
+
+func (comb *combFilter) process(input, feedback, damping float64) float64 {
+	output := comb.buffer[comb.next]
+	comb.store = output*(1-damping) + comb.store*damping
+
+	comb.buffer[comb.next] = input + comb.store*feedback
+	comb.next = (comb.next + 1) % len(comb.buffer)
+
+	return output
+}
+
+func (allpass *allpassFilter) process(input float64) float64 {
+	delayed := allpass.buffer[allpass.next]
+	output := delayed - input
+
+	allpass.buffer[allpass.next] = input + delayed*reverbAllpassFeedback
+	allpass.next = (allpass.next + 1) % len(allpass.buffer)
+
+	return output
+}
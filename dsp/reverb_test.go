@@ -0,0 +1,130 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+// the wet output of a reverb for an impulse in every channel
+func reverbImpulse(settings ReverbSettings, samplesPerSec uint32, channels uint16, seconds float64) [][]float64 {
+	buffer := make([][]float64, channels)
+	for channel := range buffer {
+		buffer[channel] = make([]float64, int(seconds*float64(samplesPerSec)))
+		buffer[channel][0] = 1
+	}
+
+	NewReverb(settings, samplesPerSec, channels).Process(buffer)
+	return buffer
+}
+
+// the peak of samples between two times in seconds, in dBFS
+func peakBetween(samples []float64, samplesPerSec uint32, start, end float64) float64 {
+	var peak float64
+	for _, sample := range samples[int(start*float64(samplesPerSec)):int(end*float64(samplesPerSec))] {
+		peak = max(peak, math.Abs(sample))
+	}
+
+	return LinearToDb(peak)
+}
+
+func TestReverbOnset(t *testing.T) {
+	tests := []struct {
+		name          string
+		samplesPerSec uint32
+		onset         int // the first comb delay of the first channel
+	}{
+		{"tuning rate", 44100, 1116},
+		{"half rate", 22050, 558},
+		{"double rate", 88200, 2232},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := reverbImpulse(ReverbSettings{RoomSize: 0.5, Wet: 1}, test.samplesPerSec, 1, 0.1)
+
+			first := -1
+			for n, sample := range output[0] {
+				if sample != 0 {
+					first = n
+					break
+				}
+			}
+
+			if first != test.onset {
+				t.Errorf("first sound at %d, want %d", first, test.onset)
+			}
+		})
+	}
+}
+
+func TestReverbDecay(t *testing.T) {
+	for _, roomSize := range []float64{0, 0.5, 1} {
+		settings := ReverbSettings{RoomSize: roomSize, Wet: 1}
+		tail := ReverbTail(settings)
+		output := reverbImpulse(settings, 44100, 1, tail+0.2)[0]
+
+		start := peakBetween(output, 44100, 0, 0.1)
+		middle := peakBetween(output, 44100, tail/2-0.05, tail/2)
+		end := peakBetween(output, 44100, tail, tail+0.2)
+
+		// still ringing half way, and 60dB down by the end of the tail
+		if middle < start-50 || middle > start-10 {
+			t.Errorf("room size %g: %.1fdB half way through the tail, from %.1fdB", roomSize, middle, start)
+		}
+
+		if end > start-60 {
+			t.Errorf("room size %g: %.1fdB after the tail, from %.1fdB", roomSize, end, start)
+		}
+	}
+}
+
+func TestReverbTail(t *testing.T) {
+	small := ReverbTail(ReverbSettings{RoomSize: 0})
+	large := ReverbTail(ReverbSettings{RoomSize: 1})
+
+	closeTo(t, "small", small, 3*1617.0/44100/-math.Log10(0.7), 1e-9)
+	closeTo(t, "large", large, 3*1617.0/44100/-math.Log10(0.98), 1e-9)
+}
+
+func TestReverbWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		width float64
+		same  bool
+	}{
+		{"mono", 0, true},
+		{"wide", 1, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := reverbImpulse(ReverbSettings{RoomSize: 0.5, Wet: 1, Width: test.width}, 44100, 2, 0.2)
+
+			var difference float64
+			for n := range output[0] {
+				difference = max(difference, math.Abs(output[0][n]-output[1][n]))
+			}
+
+			if (difference < 1e-12) != test.same {
+				t.Errorf("channels differ by up to %g", difference)
+			}
+		})
+	}
+}
+
+func TestReverbDry(t *testing.T) {
+	output := reverbImpulse(ReverbSettings{RoomSize: 0.5, Dry: 1}, 44100, 2, 0.1)
+
+	for channel, samples := range output {
+		for n, sample := range samples {
+			want := 0.0
+			if n == 0 {
+				want = 1
+			}
+
+			if sample != want {
+				t.Errorf("channel %d sample %d: got %g, want %g", channel, n, sample, want)
+			}
+		}
+	}
+}
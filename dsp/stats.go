@@ -0,0 +1,179 @@
+package dsp
+
+import (
+	"math"
+	"wave-edit/wave"
+)
+
+type Stats struct {
+	Frames      uint32
+	Duration    float64 // seconds
+	Channels    []ChannelStats
+	Correlation [][]float64 // Pearson correlation between each pair of channels, -1 to 1
+}
+
+type ChannelStats struct {
+	Peak          float64 // dBFS
+	TruePeak      float64 // dBTP
+	Rms           float64 // dBFS
+	DcOffset      float64 // Mean sample value, linear
+	CrestFactor   float64 // Peak over RMS in dB, 0 for silence
+	ZeroCrossings float64 // Sign changes per second
+	Clipped       uint64  // Samples in runs at full scale
+	NonFinite     uint64  // NaN or infinite samples, only found in float formats
+}
+
+// running sums for one channel
+type channelSums struct {
+	peak      float64
+	sum       float64
+	squares   float64
+	crossings uint64
+	last      float64
+	nonFinite uint64
+	clipped   clipFinder
+	clipRuns  []ClipRun
+	truePeak  TruePeakMeter
+}
+
+// decode a whole file and measure each channel and how the channels relate
+func MeasureStats(file *wave.WaveFile) (*Stats, error) {
+	channels := int(file.Fmt.Channels)
+	sums := make([]channelSums, channels)
+	for channel := range sums {
+		sums[channel].clipped = clipFinder{threshold: DEFAULT_CLIP_THRESHOLD, minLength: DEFAULT_CLIP_MIN_LENGTH}
+	}
+
+	// sums of products of each pair of channels
+	products := make([][]float64, channels)
+	for channel := range products {
+		products[channel] = make([]float64, channels)
+	}
+
+	var start uint32
+	err := readBlocks(file, func(buffer [][]float64) {
+		for channel, samples := range buffer {
+			channelSum := &sums[channel]
+
+			for n, sample := range samples {
+				// the rest of the measurements treat a broken sample as silence
+				if math.IsNaN(sample) || math.IsInf(sample, 0) {
+					channelSum.nonFinite++
+					sample = 0
+					samples[n] = 0
+				}
+
+				channelSum.peak = max(channelSum.peak, math.Abs(sample))
+				channelSum.sum += sample
+				channelSum.squares += sample * sample
+
+				if start+uint32(n) > 0 && math.Signbit(sample) != math.Signbit(channelSum.last) {
+					channelSum.crossings++
+				}
+				channelSum.last = sample
+
+				channelSum.clipRuns = channelSum.clipped.add(channelSum.clipRuns, uint16(channel), start+uint32(n), sample)
+			}
+
+			channelSum.truePeak.Process(samples)
+		}
+
+		for n := range planarFrames(buffer) {
+			for a := range buffer {
+				for b := a + 1; b < channels; b++ {
+					products[a][b] += buffer[a][n] * buffer[b][n]
+				}
+			}
+		}
+
+		start += uint32(planarFrames(buffer))
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	frames := float64(file.Frames())
+	stats := &Stats{
+		Frames:      file.Frames(),
+		Duration:    frames / float64(file.Fmt.SamplesPerSec),
+		Channels:    make([]ChannelStats, channels),
+		Correlation: make([][]float64, channels),
+	}
+
+	for channel := range sums {
+		channelSum := &sums[channel]
+		channelSum.clipRuns = channelSum.clipped.end(channelSum.clipRuns, uint16(channel))
+
+		var clipped uint64
+		for _, run := range channelSum.clipRuns {
+			clipped += uint64(run.Length)
+		}
+
+		// an empty file has no level, and silence has no crest factor
+		var rms, dcOffset, crestFactor, zeroCrossings float64
+		if frames > 0 {
+			rms = math.Sqrt(channelSum.squares / frames)
+			dcOffset = channelSum.sum / frames
+		}
+		if rms > 0 {
+			crestFactor = LinearToDb(channelSum.peak / rms)
+		}
+		if stats.Duration > 0 {
+			zeroCrossings = float64(channelSum.crossings) / stats.Duration
+		}
+
+		stats.Channels[channel] = ChannelStats{
+			Peak:          LinearToDb(channelSum.peak),
+			TruePeak:      channelSum.truePeak.PeakDb(),
+			Rms:           LinearToDb(rms),
+			DcOffset:      dcOffset,
+			CrestFactor:   crestFactor,
+			ZeroCrossings: zeroCrossings,
+			Clipped:       clipped,
+			NonFinite:     channelSum.nonFinite,
+		}
+	}
+
+	for a := range stats.Correlation {
+		stats.Correlation[a] = make([]float64, channels)
+		stats.Correlation[a][a] = 1
+
+		for b := range a {
+			stats.Correlation[a][b] = stats.Correlation[b][a]
+		}
+
+		for b := a + 1; b < channels; b++ {
+			stats.Correlation[a][b] = correlation(products[a][b], &sums[a], &sums[b], frames)
+		}
+	}
+
+	return stats, nil
+}
+
+// Pearson correlation from running sums, zero when either channel is constant
+func correlation(product float64, a, b *channelSums, frames float64) float64 {
+	if frames == 0 {
+		return 0
+	}
+
+	covariance := product/frames - (a.sum/frames)*(b.sum/frames)
+	varianceA := a.squares/frames - (a.sum/frames)*(a.sum/frames)
+	varianceB := b.squares/frames - (b.sum/frames)*(b.sum/frames)
+
+	if varianceA <= 0 || varianceB <= 0 {
+		return 0
+	}
+
+	return covariance / math.Sqrt(varianceA*varianceB)
+}
+
+// the sample count of every channel that is NaN or infinite
+func (stats *Stats) NonFinite() uint64 {
+	var count uint64
+	for _, channel := range stats.Channels {
+		count += channel.NonFinite
+	}
+
+	return count
+}
@@ -0,0 +1,156 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+	"wave-edit/wave"
+)
+
+// a file holding planar samples
+func planarFile(format wave.WaveFormat, samplesPerSec uint32, buffer [][]float64) *wave.WaveFile {
+	file := wave.CreateWave(format, uint16(len(buffer)), samplesPerSec)
+	file.InsertSilence(0, uint32(planarFrames(buffer)))
+	file.WriteFrames(0, buffer)
+	return file
+}
+
+func TestMeasureStatsSine(t *testing.T) {
+	file := sineFile(wave.PCM_FLOAT64, 1, 48000, 1000, -6, 1)
+
+	stats, err := MeasureStats(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	channel := stats.Channels[0]
+	closeTo(t, "duration", stats.Duration, 1, 1e-12)
+	closeTo(t, "peak", channel.Peak, -6, 1e-9)
+	closeTo(t, "true peak", channel.TruePeak, -6, 0.1)
+	closeTo(t, "rms", channel.Rms, -6-LinearToDb(math.Sqrt2), 1e-6)
+	closeTo(t, "crest factor", channel.CrestFactor, LinearToDb(math.Sqrt2), 1e-6)
+	closeTo(t, "dc offset", channel.DcOffset, 0, 1e-9)
+
+	// two crossings a cycle, give or take the samples landing on zero
+	closeTo(t, "zero crossings", channel.ZeroCrossings, 2000, 2)
+}
+
+func TestMeasureStatsDcOffset(t *testing.T) {
+	file := sineFile(wave.PCM_FLOAT64, 1, 48000, 1000, -20, 1)
+	buffer := file.NewPlanarBuffer(file.Frames())
+	file.ReadFrames(0, buffer)
+	for n := range buffer[0] {
+		buffer[0][n] += 0.25
+	}
+	file.WriteFrames(0, buffer)
+
+	stats, err := MeasureStats(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	closeTo(t, "dc offset", stats.Channels[0].DcOffset, 0.25, 1e-9)
+	closeTo(t, "zero crossings", stats.Channels[0].ZeroCrossings, 0, 0)
+}
+
+func TestMeasureStatsClipping(t *testing.T) {
+	samples := make([]float64, 100)
+
+	// a run long enough to count, a run too short, and a negative run
+	for n := 10; n < 15; n++ {
+		samples[n] = 1
+	}
+	samples[30], samples[31] = 1, 1
+	for n := 50; n < 54; n++ {
+		samples[n] = -1
+	}
+
+	stats, err := MeasureStats(planarFile(wave.PCM_16, 48000, [][]float64{samples}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clipped := stats.Channels[0].Clipped; clipped != 9 {
+		t.Errorf("got %d clipped samples, want 9", clipped)
+	}
+}
+
+func TestMeasureStatsNonFinite(t *testing.T) {
+	left := make([]float64, 100)
+	right := make([]float64, 100)
+	left[0], left[5], left[99] = math.NaN(), math.Inf(1), math.Inf(-1)
+	right[50], right[51] = 0.5, math.NaN()
+
+	stats, err := MeasureStats(planarFile(wave.PCM_FLOAT32, 48000, [][]float64{left, right}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Channels[0].NonFinite != 3 || stats.Channels[1].NonFinite != 1 || stats.NonFinite() != 4 {
+		t.Errorf("got %d and %d non-finite samples, %d in all", stats.Channels[0].NonFinite, stats.Channels[1].NonFinite, stats.NonFinite())
+	}
+
+	// broken samples measure as silence
+	if !math.IsInf(stats.Channels[0].Peak, -1) {
+		t.Errorf("left peak: got %f, want silence", stats.Channels[0].Peak)
+	}
+	closeTo(t, "right peak", stats.Channels[1].Peak, LinearToDb(0.5), 1e-9)
+}
+
+func TestMeasureStatsCorrelation(t *testing.T) {
+	signal := noise(4800, 1)
+	inverted := make([]float64, len(signal))
+	quieter := make([]float64, len(signal))
+	constant := make([]float64, len(signal))
+	for n, sample := range signal {
+		inverted[n] = -sample
+		quieter[n] = sample / 4
+		constant[n] = 0.5
+	}
+
+	stats, err := MeasureStats(planarFile(wave.PCM_FLOAT64, 48000, [][]float64{signal, inverted, quieter, constant, noise(4800, 2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		a, b      int
+		want      float64
+		tolerance float64
+	}{
+		{"itself", 0, 0, 1, 0},
+		{"inverted", 0, 1, -1, 1e-9},
+		{"inverted, other way round", 1, 0, -1, 1e-9},
+		{"quieter", 0, 2, 1, 1e-9},
+		{"constant", 0, 3, 0, 0},
+		{"unrelated", 0, 4, 0, 0.05},
+	}
+
+	for _, test := range tests {
+		closeTo(t, test.name, stats.Correlation[test.a][test.b], test.want, test.tolerance)
+	}
+}
+
+func TestMeasureStatsEmpty(t *testing.T) {
+	stats, err := MeasureStats(wave.CreateWave(wave.PCM_16, 2, 48000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for channel, channelStats := range stats.Channels {
+		for name, value := range map[string]float64{
+			"dc offset":      channelStats.DcOffset,
+			"crest factor":   channelStats.CrestFactor,
+			"zero crossings": channelStats.ZeroCrossings,
+			"correlation":    stats.Correlation[0][1],
+		} {
+			if value != 0 {
+				t.Errorf("channel %d %s: got %f, want 0", channel, name, value)
+			}
+		}
+
+		if !math.IsInf(channelStats.Rms, -1) || !math.IsInf(channelStats.Peak, -1) {
+			t.Errorf("channel %d: got rms %f and peak %f, want silence", channel, channelStats.Rms, channelStats.Peak)
+		}
+	}
+}
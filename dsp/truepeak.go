@@ -0,0 +1,68 @@
+package dsp
+
+import "math"
+
+// oversampling factor and taps per phase of the true peak interpolator
+const truePeakFactor = 4
+const truePeakTaps = 12
+
+// polyphase lowpass used to find peaks between samples, as in ITU-R BS.1770 annex 2
+var truePeakPhases = truePeakFilter()
+
+// finds the largest absolute value of a signal oversampled 4 times
+type TruePeakMeter struct {
+	history [truePeakTaps]float64
+	next    int
+	Peak    float64 // Largest absolute value so far, linear
+}
+
+func truePeakFilter() [truePeakFactor][truePeakTaps]float64 {
+	var phases [truePeakFactor][truePeakTaps]float64
+	length := truePeakFactor * truePeakTaps
+	center := float64(length-1) / 2
+
+	for n := range length {
+		x := (float64(n) - center) / truePeakFactor
+		window := 0.42 - 0.5*math.Cos(2*math.Pi*float64(n)/float64(length-1)) + 0.08*math.Cos(4*math.Pi*float64(n)/float64(length-1))
+
+		sinc := 1.0
+		if x != 0 {
+			sinc = math.Sin(math.Pi*x) / (math.Pi * x)
+		}
+
+		phases[n%truePeakFactor][n/truePeakFactor] = sinc * window
+	}
+
+	return phases
+}
+
+func (meter *TruePeakMeter) Process(samples []float64) {
+	for _, sample := range samples {
+		meter.Peak = max(meter.Peak, math.Abs(sample))
+
+		meter.history[meter.next] = sample
+		meter.next = (meter.next + 1) % truePeakTaps
+
+		for _, phase := range truePeakPhases {
+			var sum float64
+			for tap, coefficient := range phase {
+				sum += coefficient * meter.history[(meter.next+truePeakTaps-1-tap)%truePeakTaps]
+			}
+
+			meter.Peak = max(meter.Peak, math.Abs(sum))
+		}
+	}
+}
+
+// the true peak in dBTP
+func (meter *TruePeakMeter) PeakDb() float64 {
+	return LinearToDb(meter.Peak)
+}
+
+func LinearToDb(linear float64) float64 {
+	return 20 * math.Log10(linear)
+}
+
+func DbToLinear(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -1,75 +1,152 @@
 package main
 
 import (
+	"errors"
 	"math"
+	"wave-edit/dsp"
 	"wave-edit/wave"
 )
 
+var ErrEffectOutOfRange = errors.New("effect extends past the end of the file")
+
 func applyEffect(wave *wave.WaveFile, startTime, endTime, beatTime float64) error {
 	start := uint32(startTime * float64(wave.Fmt.SamplesPerSec))
 	end := uint32(endTime * float64(wave.Fmt.SamplesPerSec))
 	beat := uint32(beatTime * float64(wave.Fmt.SamplesPerSec))
 
-	for channel := range wave.Fmt.Channels {
-		samples, err := wave.GetSamples(channel, start, end)
-		if err != nil {
-			return err
-		}
+	if end > wave.Frames() {
+		return ErrEffectOutOfRange
+	}
 
-		for n := range samples {
-			// ignore last 2 samples
-			if n < len(samples)-2 {
-				samples[n] = effectSample(samples, uint32(n), end-start, beat)
-			}
+	buffer := wave.NewPlanarBuffer(end - start)
+
+	_, err := wave.ReadFrames(start, buffer)
+	if err != nil {
+		return err
+	}
+
+	// the first half of the region plays all of it at an increasing speed
+	spedUp, err := speedUp(wave, start, end)
+	if err != nil {
+		return err
+	}
+
+	width := end - start
+	for channel, samples := range buffer {
+		// ignore last 2 samples
+		last := max(2, len(samples)) - 2
+		copy(samples[:min(int(width/2), last)], spedUp[channel])
+
+		// the second half repeats the last two beats
+		for n := width / 2; int(n) < last; n++ {
+			samples[n] = repeatEffect(samples, n, width, beat)
 		}
 
-		smoothSamples := make([]float64, end-start)
-		for n := range samples {
-			smoothSamples[n] = samples[n]
-
-			// ignore first 2 and last two samples
-			if n > 1 && n < len(samples)-2 {
-				// detect clipping
-				if math.Abs(samples[n-2]-samples[n+2]) > 0.3 {
-					// smooth samples
-					smoothSamples[n] = (samples[n-2] + samples[n-1] + samples[n] + samples[n+1] + samples[n+2]) / 5
-				}
-			}
+		// rebuild any peaks that were clipped
+		runs := dsp.FindClipping(samples, dsp.DEFAULT_CLIP_THRESHOLD, dsp.DEFAULT_CLIP_MIN_LENGTH)
+		dsp.Declip(samples, runs, dsp.DECLIP_AR)
+	}
+
+	// rebuilt peaks can go past full scale, turn the whole file down so integer formats don't clip them again
+	// and the region keeps its level against the rest, as DeclipFile does
+	peak := 0.0
+	for _, samples := range buffer {
+		for _, sample := range samples {
+			peak = max(peak, math.Abs(sample))
 		}
+	}
 
-		err = wave.SetSamples(channel, start, smoothSamples)
+	if peak > 1 && !wave.Fmt.Format.IsFloat() {
+		err = dsp.ProcessFile(wave, dsp.Gain(1/peak))
 		if err != nil {
 			return err
 		}
+
+		dsp.Gain(1 / peak).Process(buffer)
+	}
+
+	return wave.WriteFrames(start, buffer)
+}
+
+// run a processor over the frames [start, end), letting it ring on for tail frames over what follows
+// the output is moved back by the processor's latency so it lines up with the input
+func applyProcessor(wave *wave.WaveFile, processor dsp.Processor, start, end, tail uint32) error {
+	if end > wave.Frames() {
+		return ErrEffectOutOfRange
+	}
+
+	length := end - start
+	tail = min(tail, wave.Frames()-end)
+	latency := processor.Latency()
+
+	// the effect keeps sounding with nothing more going in, the latency is flushed out the same way
+	buffer := wave.NewPlanarBuffer(length + tail + latency)
+	_, err := wave.ReadFrames(start, sliceFrames(buffer, 0, length))
+	if err != nil {
+		return err
+	}
+
+	processor.Process(buffer)
+
+	err = wave.WriteFrames(start, sliceFrames(buffer, latency, latency+length))
+	if err != nil {
+		return err
+	}
+
+	// the ringing is added to what is there
+	ringing := sliceFrames(buffer, latency+length, latency+length+tail)
+	following := wave.NewPlanarBuffer(tail)
+	_, err = wave.ReadFrames(end, following)
+	if err != nil {
+		return err
+	}
+
+	for channel, samples := range following {
+		for n := range samples {
+			samples[n] += ringing[channel][n]
+		}
 	}
 
-	return nil
+	return wave.WriteFrames(end, following)
 }
 
-func effectSample(samples []float64, location, width, beat uint32) float64 {
-	if location < width/2 {
-		// for the first half of the effected region
-		return speedUpEffect(samples, location, width)
-	} else {
-		// for the second half of the effected region
-		return repeatEffect(samples, location, width, beat)
+// frames [start, end) of a planar buffer, sharing its samples
+func sliceFrames(buffer [][]float64, start, end uint32) [][]float64 {
+	sliced := make([][]float64, len(buffer))
+	for channel, samples := range buffer {
+		sliced[channel] = samples[start:end]
 	}
+
+	return sliced
 }
 
-func speedUpEffect(samples []float64, location, width uint32) float64 {
-	// finds the index of sample 2x^2 + x
-	// in other words, sound will play at an increasing speed
-	x := float64(location) / float64(width)
-	y := 2*(x*x) + x
-	y *= float64(width)
+// the frames [start, end) played at a speed rising from 1 to 3, finishing in half the time
+// frames play at source position 2x^2 + x of the region for output position x, so the speed
+// is 4x + 1, or sqrt(1 + 8p) at source position p
+func speedUp(file *wave.WaveFile, start, end uint32) ([][]float64, error) {
+	clip, err := file.Copy(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// rebuilt peaks are found by clipping later, so keep what goes past full scale
+	clip, err = clip.Reformat(wave.PCM_FLOAT64)
+	if err != nil {
+		return nil, err
+	}
 
-	// smoothly mix between samples
-	integer, fractional := math.Modf(y)
-	leftSample := samples[uint32(integer)]
-	rightSample := samples[uint32(integer)+1]
-	sample := fadeBetween(leftSample, rightSample, fractional)
+	width := float64(end - start)
+	rate := float64(file.Fmt.SamplesPerSec)
+	err = clip.Varispeed(0, clip.Frames(), func(seconds float64) float64 {
+		return math.Sqrt(1 + 8*seconds*rate/width)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return sample
+	buffer := clip.NewPlanarBuffer(min(clip.Frames(), (end-start)/2))
+	_, err = clip.ReadFrames(0, buffer)
+	return buffer, err
 }
 
 func repeatEffect(samples []float64, location, width, beat uint32) float64 {
@@ -81,14 +158,10 @@ func repeatEffect(samples []float64, location, width, beat uint32) float64 {
 	y := lastTwoBeats + location%twoBeats
 	
 	// I don't want to talk about it...
-	if samples[y] == samples[y-1] && samples[y] == samples[y-1] && samples[y] == samples[y-2] {
+	if samples[y] == samples[y-1] && samples[y] == samples[y-2] {
 		return samples[location]
 	}
 	
 	// return the repeating sample
 	return samples[y]
 }
-
-func fadeBetween(x, y, fade float64) float64 {
-	return x*(1-fade) + y*fade
-}
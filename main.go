@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"wave-edit/riff"
 	"wave-edit/wave"
@@ -14,9 +15,21 @@ import (
 
 var ErrExpectedWave = errors.New("expected WAVE file")
 
+// used when the file carries no tempo
+const defaultSecondsPerBeat = 60.0 / 125.0 // 125 bpm
+
 var mainWindow fyne.Window
 
 func main() {
+	if len(os.Args) > 1 {
+		err := runCommand(os.Args[1], os.Args[2:])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	app := app.New()
 	mainWindow = app.NewWindow("WAVE edit")
 
@@ -32,38 +45,62 @@ func handleFile(_ fyne.Position, uris []fyne.URI) {
 		dialog.NewInformation("Too many files", "Only reading the first file.", mainWindow).Show()
 	}
 
-	file, err := os.Open(uri.Path())
+	wave, err := openWave(uri.Path())
 	if err != nil {
 		dialog.NewError(err, mainWindow).Show()
 		return
 	}
+
+	handleWave(wave)
+}
+
+func openWave(path string) (*wave.WaveFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
 	riffChunk, err := riff.DeserializerRiff(file)
-
 	if err != nil {
-		dialog.NewError(err, mainWindow).Show()
-		return
+		return nil, err
 	}
 
 	wave, ok := riffChunk.(*wave.WaveFile)
 	if !ok {
-		dialog.NewError(ErrExpectedWave, mainWindow).Show()
-	} else {
-		handleWave(wave)
+		return nil, ErrExpectedWave
+	}
+
+	return wave, nil
+}
+
+func saveWave(path string, wave *wave.WaveFile) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = wave.Serialize(file)
+	if err != nil {
+		file.Close()
+		return err
 	}
+
+	return file.Close()
 }
 
 func handleWave(wave *wave.WaveFile) {
 	processingDialog := dialog.NewInformation("Processing", "Working...", mainWindow)
 	processingDialog.Show()
 
+	beat := secondsPerBeat(wave)
+
 	go func() {
-		effect(wave, 40*4, 16)
-		effect(wave, 48*4, 8)
-		effect(wave, 58*4, 16)
-		effect(wave, 70*4, 24)
-		effect(wave, 88*4, 8)
+		effect(wave, beat, 40*4, 16)
+		effect(wave, beat, 48*4, 8)
+		effect(wave, beat, 58*4, 16)
+		effect(wave, beat, 70*4, 24)
+		effect(wave, beat, 88*4, 8)
 
 		fyne.Do(func() {
 			processingDialog.Dismiss()
@@ -84,8 +121,18 @@ func handleWave(wave *wave.WaveFile) {
 	}()
 }
 
-func effect(wave *wave.WaveFile, startBeat, beatLength float64) {
-	secondsPerBeat := 60.0 / 125.0 // 125 bpm
+// seconds per beat from the file's acid chunk, or the default
+func secondsPerBeat(wave *wave.WaveFile) float64 {
+	if wave.Acid != nil {
+		if seconds, ok := wave.Acid.SecondsPerBeat(); ok {
+			return seconds
+		}
+	}
+
+	return defaultSecondsPerBeat
+}
+
+func effect(wave *wave.WaveFile, secondsPerBeat, startBeat, beatLength float64) {
 	err := applyEffect(wave, startBeat*secondsPerBeat, (startBeat+beatLength)*secondsPerBeat, secondsPerBeat)
 
 	if err != nil {
@@ -43,5 +43,29 @@ func DeserializeChunk[T Chunk](reader io.Reader, handler ChunkDeserializer[T]) (
 		return nothing, err
 	}
 
+	// a missing pad byte at the very end of a file is common, so tolerate it
+	if dataSize%2 == 1 {
+		_, err = reader.Read(make([]byte, 1))
+		if err != nil && err != io.EOF {
+			return nothing, err
+		}
+	}
+
 	return chunk, nil
 }
+
+// the space a chunk takes in its parent, including the pad byte after odd sizes
+func PaddedSize(chunk Chunk) uint32 {
+	size := chunk.Size()
+	return size + size%2
+}
+
+// write the pad byte that follows a chunk of odd size
+func SerializePadding(writer io.Writer, size uint32) error {
+	if size%2 == 0 {
+		return nil
+	}
+
+	_, err := writer.Write([]byte{0})
+	return err
+}
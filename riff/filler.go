@@ -0,0 +1,26 @@
+package riff
+
+import "io"
+
+// a chunk of zeros reserving space, such as JUNK or PAD
+type FillerChunk struct {
+	ChunkId  FourCC
+	DataSize uint32
+}
+
+const JunkChunkId = "JUNK"
+const PadChunkId = "PAD "
+
+func (chunk *FillerChunk) Serialize(writer io.Writer) error {
+	err := SerializeChunkHeader(writer, chunk.ChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(make([]byte, chunk.DataSize))
+	return err
+}
+
+func (chunk *FillerChunk) Size() uint32 {
+	return 8 + chunk.DataSize
+}
@@ -17,27 +17,33 @@ var ErrUnexpectedListType = errors.New("unexpected list chunk type")
 func ListChunkDeserializer[T Chunk](reader io.Reader, id FourCC, size uint32, expectedType FourCC, elementHandler ChunkDeserializer[T]) (*ListChunk[T], error) {
 	if id != ListChunkId {
 		return nil, ErrUnexpectedChunkId
+	} else if size < 4 {
+		return nil, ErrUnexpectedEnd
 	}
 
 	listType, err := DeserializeFourCC(reader)
-	size -= 4
-
 	if err != nil {
 		return nil, err
 	} else if listType != expectedType {
 		return nil, ErrUnexpectedListType
 	}
 
+	return ListElementsDeserializer(reader, listType, size-4, elementHandler)
+}
+
+// read the elements of a list chunk after its type, size doesn't include the type
+func ListElementsDeserializer[T Chunk](reader io.Reader, listType FourCC, size uint32, elementHandler ChunkDeserializer[T]) (*ListChunk[T], error) {
 	elements := []T{}
 	for size > 0 {
 		elm, err := DeserializeChunk(reader,
 			func(reader io.Reader, elmId FourCC, elmSize uint32) (T, error) {
-				if size < elmSize {
+				paddedSize := 8 + elmSize + elmSize%2
+				if size < paddedSize {
 					var nothing T
 					return nothing, ErrReadTooMuch
 				}
 
-				size -= elmSize
+				size -= paddedSize
 				return elementHandler(reader, elmId, elmSize)
 			})
 
@@ -65,7 +71,15 @@ func (chunk *ListChunk[T]) Serialize(writer io.Writer) error {
 	}
 
 	for _, child := range chunk.Chunks {
-		child.Serialize(writer)
+		err = child.Serialize(writer)
+		if err != nil {
+			return err
+		}
+
+		err = SerializePadding(writer, child.Size())
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -75,7 +89,7 @@ func (chunk *ListChunk[T]) Size() uint32 {
 	var size uint32 = 12
 
 	for _, child := range chunk.Chunks {
-		size += child.Size()
+		size += PaddedSize(child)
 	}
 
 	return size
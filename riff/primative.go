@@ -4,12 +4,14 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"math"
 	"reflect"
 )
 
 type FourCC string
 
 var ErrInvalidFourCC = errors.New("invalid four char code, wrong size")
+var ErrStringTooLong = errors.New("string too long for its field")
 
 func SerializeStruct(writer io.Writer, data any) error {
 	value := reflect.ValueOf(data)
@@ -34,6 +36,18 @@ func SerializeStruct(writer io.Writer, data any) error {
 				return err
 			}
 
+		case reflect.TypeFor[uint8]():
+			err := SerializeByte(writer, uint8(value.Field(i).Uint()))
+			if err != nil {
+				return err
+			}
+
+		case reflect.TypeFor[float32]():
+			err := SerializeFloat(writer, float32(value.Field(i).Float()))
+			if err != nil {
+				return err
+			}
+
 		}
 	}
 
@@ -69,6 +83,22 @@ func DeserializeStruct[T any](reader io.Reader) (T, error) {
 			}
 
 			value.Field(i).SetString(string(fourCC))
+
+		case reflect.TypeFor[uint8]():
+			octet, err := DeserializeByte(reader)
+			if err != nil {
+				return nothing, err
+			}
+
+			value.Field(i).SetUint(uint64(octet))
+
+		case reflect.TypeFor[float32]():
+			float, err := DeserializeFloat(reader)
+			if err != nil {
+				return nothing, err
+			}
+
+			value.Field(i).SetFloat(float64(float))
 		}
 	}
 
@@ -119,6 +149,19 @@ func DeserializeDword(reader io.Reader) (uint32, error) {
 	return binary.LittleEndian.Uint32(buffer[:]), nil
 }
 
+func SerializeFloat(writer io.Writer, float float32) error {
+	return SerializeDword(writer, math.Float32bits(float))
+}
+
+func DeserializeFloat(reader io.Reader) (float32, error) {
+	dword, err := DeserializeDword(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Float32frombits(dword), nil
+}
+
 func SerializeWord(writer io.Writer, word uint16) error {
 	var buffer [2]byte
 	binary.LittleEndian.PutUint16(buffer[:], word)
@@ -140,3 +183,74 @@ func DeserializeWord(reader io.Reader) (uint16, error) {
 
 	return binary.LittleEndian.Uint16(buffer[:]), nil
 }
+
+func SerializeByte(writer io.Writer, value uint8) error {
+	_, err := writer.Write([]byte{value})
+	return err
+}
+
+func DeserializeByte(reader io.Reader) (uint8, error) {
+	var buffer [1]byte
+	n, err := reader.Read(buffer[:])
+
+	if n < 1 {
+		return 0, ErrUnexpectedEnd
+	} else if err != nil && err != io.EOF {
+		return 0, err
+	}
+
+	return buffer[0], nil
+}
+
+func SerializeBytes(writer io.Writer, data []byte) error {
+	_, err := writer.Write(data)
+	return err
+}
+
+func DeserializeBytes(reader io.Reader, size uint32) ([]byte, error) {
+	buffer := make([]byte, size)
+	n, err := io.ReadFull(reader, buffer)
+
+	if n < int(size) {
+		return nil, ErrUnexpectedEnd
+	} else if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return buffer, nil
+}
+
+// write a null terminated string
+func SerializeZString(writer io.Writer, text string) error {
+	_, err := writer.Write(append([]byte(text), 0))
+	return err
+}
+
+// read size bytes holding a string, stopping at the first null
+func DeserializeZString(reader io.Reader, size uint32) (string, error) {
+	data, err := DeserializeBytes(reader, size)
+	if err != nil {
+		return "", err
+	}
+
+	for n, char := range data {
+		if char == 0 {
+			return string(data[:n]), nil
+		}
+	}
+
+	return string(data), nil
+}
+
+// write a string into a field of size bytes, padding the rest with nulls
+func SerializeFixedString(writer io.Writer, text string, size uint32) error {
+	if uint32(len(text)) > size {
+		return ErrStringTooLong
+	}
+
+	buffer := make([]byte, size)
+	copy(buffer, text)
+
+	_, err := writer.Write(buffer)
+	return err
+}
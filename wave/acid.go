@@ -0,0 +1,107 @@
+package wave
+
+import (
+	"io"
+	"wave-edit/riff"
+)
+
+type AcidChunk struct {
+	Flags            uint32  // ACID_ flags
+	RootNote         uint16  // MIDI note of the loop
+	Reserved1        uint16  // Usually 0x8000
+	Reserved2        float32 // Usually 0
+	Beats            uint32  // Number of beats in the file
+	MeterDenominator uint16  // Beat unit of the time signature
+	MeterNumerator   uint16  // Beats per bar of the time signature
+	Tempo            float32 // Beats per minute
+}
+
+const acidChunkId = "acid"
+
+// tempos outside these in beats per minute are taken to be garbage rather than music
+const MIN_TEMPO = 1
+const MAX_TEMPO = 1000
+
+const (
+	ACID_ONE_SHOT uint32 = 1 << iota
+	ACID_ROOT_NOTE_SET
+	ACID_STRETCH
+	ACID_DISK_BASED
+	ACID_HIGH_OCTAVE
+)
+
+func acidDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*AcidChunk, error) {
+	if id != acidChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 24 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	chunk, err := riff.DeserializeStruct[AcidChunk](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > 24 {
+		_, err = riff.DeserializeBytes(reader, size-24)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &chunk, nil
+}
+
+func (chunk *AcidChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, acidChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeStruct(writer, *chunk)
+}
+
+func (chunk *AcidChunk) Size() uint32 {
+	return 8 + 24
+}
+
+// a looping acid chunk in 4/4 at a tempo
+func NewAcid(tempo float32, beats uint32) *AcidChunk {
+	return &AcidChunk{
+		Flags:            ACID_STRETCH,
+		Reserved1:        0x8000,
+		Beats:            beats,
+		MeterDenominator: 4,
+		MeterNumerator:   4,
+		Tempo:            tempo,
+	}
+}
+
+func (chunk *AcidChunk) IsOneShot() bool {
+	return chunk.Flags&ACID_ONE_SHOT != 0
+}
+
+// root note of the loop, ok is false when none was set
+func (chunk *AcidChunk) Root() (note uint16, ok bool) {
+	return chunk.RootNote, chunk.Flags&ACID_ROOT_NOTE_SET != 0
+}
+
+func (chunk *AcidChunk) SetRoot(note uint16) {
+	chunk.RootNote = note
+	chunk.Flags |= ACID_ROOT_NOTE_SET
+}
+
+// whether the tempo is a number of beats per minute that can be used
+func (chunk *AcidChunk) HasTempo() bool {
+	// also false for NaN
+	return chunk.Tempo >= MIN_TEMPO && chunk.Tempo <= MAX_TEMPO
+}
+
+// the length of a beat, ok is false without a usable tempo
+func (chunk *AcidChunk) SecondsPerBeat() (seconds float64, ok bool) {
+	if !chunk.HasTempo() {
+		return 0, false
+	}
+
+	return 60 / float64(chunk.Tempo), true
+}
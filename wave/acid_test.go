@@ -0,0 +1,70 @@
+package wave
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAcidChunk(t *testing.T) {
+	acid := *NewAcid(120, 8)
+
+	tests := []struct {
+		name  string
+		extra int
+	}{
+		{"standard", 0},
+		{"trailing bytes", 4},
+		{"odd trailing bytes", 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := append(rawStruct(acid), make([]byte, test.extra)...)
+			wave := loadWave(t, rawStereoWave(rawChunk(acidChunkId, data)))
+
+			for _, loaded := range []*AcidChunk{wave.Acid, roundTrip(t, wave).Acid} {
+				if loaded == nil || *loaded != acid {
+					t.Errorf("got %+v, want %+v", loaded, acid)
+				}
+			}
+		})
+	}
+}
+
+func TestAcidTempo(t *testing.T) {
+	tests := []struct {
+		name    string
+		tempo   float32
+		seconds float64
+		ok      bool
+	}{
+		{"120 bpm", 120, 0.5, true},
+		{"slowest", MIN_TEMPO, 60, true},
+		{"fastest", MAX_TEMPO, 0.06, true},
+		{"zero", 0, 0, false},
+		{"negative", -120, 0, false},
+		{"too fast", MAX_TEMPO * 2, 0, false},
+		{"not a number", float32(math.NaN()), 0, false},
+		{"infinite", float32(math.Inf(1)), 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := rampWave(PCM_16, 1, 100)
+			wave.Acid = NewAcid(test.tempo, 1)
+
+			seconds, ok := wave.Acid.SecondsPerBeat()
+			if ok != test.ok || math.Abs(seconds-test.seconds) > 1e-12 {
+				t.Errorf("got %f seconds, %t, want %f, %t", seconds, ok, test.seconds, test.ok)
+			}
+
+			// a stretch FitTempo allows when the tempo is usable
+			err := wave.FitTempo(float64(test.tempo) * 1.5)
+			if ok && err != nil {
+				t.Errorf("fit tempo: %v", err)
+			} else if !ok && err != ErrNoTempo {
+				t.Errorf("fit tempo: got error %v, want %v", err, ErrNoTempo)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package wave
+
+import (
+	"io"
+	"wave-edit/riff"
+)
+
+// a labl or note chunk, naming or describing a cue point
+type LabelChunk struct {
+	ChunkId riff.FourCC
+	CueId   uint32
+	Text    string
+}
+
+// a ltxt chunk, giving a cue point a length
+type LabeledTextChunk struct {
+	CueId        uint32      // Cue point the text belongs to
+	SampleLength uint32      // Frames covered from the cue point
+	Purpose      riff.FourCC // Use of the text, such as "rgn "
+	Country      uint16      // Country code
+	Language     uint16      // Language code
+	Dialect      uint16      // Dialect code
+	CodePage     uint16      // Code page of the text
+	Text         string
+}
+
+type rawLabeledText struct {
+	CueId        uint32
+	SampleLength uint32
+	Purpose      riff.FourCC
+	Country      uint16
+	Language     uint16
+	Dialect      uint16
+	CodePage     uint16
+}
+
+const adtlListType = "adtl"
+const labelChunkId = "labl"
+const noteChunkId = "note"
+const labeledTextChunkId = "ltxt"
+const regionPurpose = "rgn "
+
+func adtlDeserializer(reader io.Reader, id riff.FourCC, size uint32) (riff.Chunk, error) {
+	switch id {
+	case labelChunkId, noteChunkId:
+		return labelDeserializer(reader, id, size)
+	case labeledTextChunkId:
+		return labeledTextDeserializer(reader, id, size)
+	default:
+		return riff.IgnoreDeserializer(reader, id, size)
+	}
+}
+
+func labelDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*LabelChunk, error) {
+	if size < 4 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	cueId, err := riff.DeserializeDword(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := riff.DeserializeZString(reader, size-4)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabelChunk{
+		ChunkId: id,
+		CueId:   cueId,
+		Text:    text,
+	}, nil
+}
+
+func (chunk *LabelChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, chunk.ChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeDword(writer, chunk.CueId)
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeZString(writer, chunk.Text)
+}
+
+func (chunk *LabelChunk) Size() uint32 {
+	return 8 + 4 + uint32(len(chunk.Text)) + 1
+}
+
+func labeledTextDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*LabeledTextChunk, error) {
+	if id != labeledTextChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 20 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	raw, err := riff.DeserializeStruct[rawLabeledText](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := riff.DeserializeZString(reader, size-20)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabeledTextChunk{
+		CueId:        raw.CueId,
+		SampleLength: raw.SampleLength,
+		Purpose:      raw.Purpose,
+		Country:      raw.Country,
+		Language:     raw.Language,
+		Dialect:      raw.Dialect,
+		CodePage:     raw.CodePage,
+		Text:         text,
+	}, nil
+}
+
+func (chunk *LabeledTextChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, labeledTextChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeStruct(writer, rawLabeledText{
+		CueId:        chunk.CueId,
+		SampleLength: chunk.SampleLength,
+		Purpose:      chunk.Purpose,
+		Country:      chunk.Country,
+		Language:     chunk.Language,
+		Dialect:      chunk.Dialect,
+		CodePage:     chunk.CodePage,
+	})
+	if err != nil {
+		return err
+	}
+
+	if chunk.Text == "" {
+		return nil
+	}
+
+	return riff.SerializeZString(writer, chunk.Text)
+}
+
+func (chunk *LabeledTextChunk) Size() uint32 {
+	if chunk.Text == "" {
+		return 8 + 20
+	}
+
+	return 8 + 20 + uint32(len(chunk.Text)) + 1
+}
@@ -0,0 +1,217 @@
+package wave
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"wave-edit/riff"
+)
+
+type BroadcastChunk struct {
+	Description          string   // Free description of the sound, up to 256 characters
+	Originator           string   // Name of the originator, up to 32 characters
+	OriginatorReference  string   // Unambiguous reference from the originator, up to 32 characters
+	OriginationDate      string   // Date of creation as yyyy-mm-dd
+	OriginationTime      string   // Time of creation as hh:mm:ss
+	TimeReference        uint64   // Samples since midnight of the first sample
+	Version              uint16   // BWF version, 0, 1 or 2
+	Umid                 [64]byte // SMPTE UMID, from version 1
+	LoudnessValue        int16    // Integrated loudness in 0.01 LUFS, from version 2
+	LoudnessRange        int16    // Loudness range in 0.01 LU, from version 2
+	MaxTruePeakLevel     int16    // Maximum true peak in 0.01 dBTP, from version 2
+	MaxMomentaryLoudness int16    // Highest momentary loudness in 0.01 LUFS, from version 2
+	MaxShortTermLoudness int16    // Highest short-term loudness in 0.01 LUFS, from version 2
+	CodingHistory        string   // Lines describing each coding process applied
+}
+
+type rawBroadcastTail struct {
+	TimeReferenceLow  uint32
+	TimeReferenceHigh uint32
+	Version           uint16
+}
+
+type rawBroadcastLoudness struct {
+	LoudnessValue        uint16
+	LoudnessRange        uint16
+	MaxTruePeakLevel     uint16
+	MaxMomentaryLoudness uint16
+	MaxShortTermLoudness uint16
+}
+
+const broadcastChunkId = "bext"
+
+// size of everything before the coding history
+const broadcastFixedSize = 602
+
+var ErrBroadcastFieldTooLong = errors.New("broadcast wave field too long")
+var ErrBroadcastDate = errors.New("broadcast wave date must be yyyy-mm-dd")
+var ErrBroadcastTime = errors.New("broadcast wave time must be hh:mm:ss")
+var ErrBroadcastVersion = errors.New("broadcast wave field not in its version")
+
+// the spec allows any of these separators between date and time parts
+var broadcastDatePattern = regexp.MustCompile(`^[0-9]{4}[-_:. ][0-9]{2}[-_:. ][0-9]{2}$`)
+var broadcastTimePattern = regexp.MustCompile(`^[0-9]{2}[-_:. ][0-9]{2}[-_:. ][0-9]{2}$`)
+
+func broadcastDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*BroadcastChunk, error) {
+	if id != broadcastChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < broadcastFixedSize {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	chunk := &BroadcastChunk{}
+	var err error
+
+	fields := []struct {
+		text *string
+		size uint32
+	}{
+		{&chunk.Description, 256},
+		{&chunk.Originator, 32},
+		{&chunk.OriginatorReference, 32},
+		{&chunk.OriginationDate, 10},
+		{&chunk.OriginationTime, 8},
+	}
+
+	for _, field := range fields {
+		*field.text, err = riff.DeserializeZString(reader, field.size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tail, err := riff.DeserializeStruct[rawBroadcastTail](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.TimeReference = uint64(tail.TimeReferenceHigh)<<32 | uint64(tail.TimeReferenceLow)
+	chunk.Version = tail.Version
+
+	umid, err := riff.DeserializeBytes(reader, 64)
+	if err != nil {
+		return nil, err
+	}
+	chunk.Umid = [64]byte(umid)
+
+	loudness, err := riff.DeserializeStruct[rawBroadcastLoudness](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.LoudnessValue = int16(loudness.LoudnessValue)
+	chunk.LoudnessRange = int16(loudness.LoudnessRange)
+	chunk.MaxTruePeakLevel = int16(loudness.MaxTruePeakLevel)
+	chunk.MaxMomentaryLoudness = int16(loudness.MaxMomentaryLoudness)
+	chunk.MaxShortTermLoudness = int16(loudness.MaxShortTermLoudness)
+
+	// reserved
+	_, err = riff.DeserializeBytes(reader, 180)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk.CodingHistory, err = riff.DeserializeZString(reader, size-broadcastFixedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+// check every field fits the chunk layout and its version
+// Serialize doesn't check, so files read with fields outside the spec still save as they were
+func (chunk *BroadcastChunk) Validate() error {
+	if len(chunk.Description) > 256 || len(chunk.Originator) > 32 || len(chunk.OriginatorReference) > 32 {
+		return ErrBroadcastFieldTooLong
+	} else if chunk.OriginationDate != "" && !broadcastDatePattern.MatchString(chunk.OriginationDate) {
+		return ErrBroadcastDate
+	} else if chunk.OriginationTime != "" && !broadcastTimePattern.MatchString(chunk.OriginationTime) {
+		return ErrBroadcastTime
+	} else if chunk.Version > 2 {
+		return ErrBroadcastVersion
+	}
+
+	if chunk.Version < 1 && chunk.Umid != [64]byte{} {
+		return ErrBroadcastVersion
+	}
+
+	hasLoudness := chunk.LoudnessValue != 0 || chunk.LoudnessRange != 0 || chunk.MaxTruePeakLevel != 0 ||
+		chunk.MaxMomentaryLoudness != 0 || chunk.MaxShortTermLoudness != 0
+	if chunk.Version < 2 && hasLoudness {
+		return ErrBroadcastVersion
+	}
+
+	return nil
+}
+
+func (chunk *BroadcastChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, broadcastChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	fields := []struct {
+		text string
+		size uint32
+	}{
+		{chunk.Description, 256},
+		{chunk.Originator, 32},
+		{chunk.OriginatorReference, 32},
+		{chunk.OriginationDate, 10},
+		{chunk.OriginationTime, 8},
+	}
+
+	for _, field := range fields {
+		err = riff.SerializeFixedString(writer, field.text, field.size)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = riff.SerializeStruct(writer, rawBroadcastTail{
+		TimeReferenceLow:  uint32(chunk.TimeReference),
+		TimeReferenceHigh: uint32(chunk.TimeReference >> 32),
+		Version:           chunk.Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeBytes(writer, chunk.Umid[:])
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeStruct(writer, rawBroadcastLoudness{
+		LoudnessValue:        uint16(chunk.LoudnessValue),
+		LoudnessRange:        uint16(chunk.LoudnessRange),
+		MaxTruePeakLevel:     uint16(chunk.MaxTruePeakLevel),
+		MaxMomentaryLoudness: uint16(chunk.MaxMomentaryLoudness),
+		MaxShortTermLoudness: uint16(chunk.MaxShortTermLoudness),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeBytes(writer, make([]byte, 180))
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeBytes(writer, []byte(chunk.CodingHistory))
+}
+
+func (chunk *BroadcastChunk) Size() uint32 {
+	return 8 + broadcastFixedSize + uint32(len(chunk.CodingHistory))
+}
+
+// seconds since midnight of the first sample
+func (chunk *BroadcastChunk) TimeReferenceSeconds(samplesPerSec uint32) float64 {
+	return float64(chunk.TimeReference) / float64(samplesPerSec)
+}
+
+// add a line to the coding history, such as "A=PCM,F=48000,W=24,M=stereo,T=wave-edit"
+func (chunk *BroadcastChunk) AppendCodingHistory(line string) {
+	chunk.CodingHistory += line + "\r\n"
+}
@@ -0,0 +1,110 @@
+package wave
+
+import (
+	"bytes"
+	"testing"
+)
+
+func rawBroadcast(chunk BroadcastChunk, codingHistory []byte) []byte {
+	var buffer bytes.Buffer
+	chunk.CodingHistory = ""
+	chunk.Serialize(&buffer)
+
+	// replace the header with one counting the coding history as given
+	data := append(buffer.Bytes()[8:], codingHistory...)
+	return rawChunk(broadcastChunkId, data)
+}
+
+func TestBroadcastChunk(t *testing.T) {
+	broadcast := BroadcastChunk{
+		Description:      "description",
+		Originator:       "originator",
+		OriginationDate:  "2024-01-02",
+		OriginationTime:  "03:04:05",
+		TimeReference:    1 << 33,
+		Version:          2,
+		LoudnessValue:    -2300,
+		MaxTruePeakLevel: -100,
+	}
+
+	tests := []struct {
+		name          string
+		codingHistory []byte
+		want          string
+	}{
+		{"no coding history", nil, ""},
+		{"coding history", []byte("A=PCM,F=48000\r\n"), "A=PCM,F=48000\r\n"},
+		{"null terminated", []byte("A=PCM,F=48000\r\n\x00"), "A=PCM,F=48000\r\n"},
+		{"null padded", append([]byte("A=PCM\r\n"), make([]byte, 256)...), "A=PCM\r\n"},
+		{"odd null padded", append([]byte("A=PCM\r\n"), make([]byte, 3)...), "A=PCM\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawWave(
+				rawBroadcast(broadcast, test.codingHistory),
+				rawFmt(PCM_FORMAT_TAG, 2, 44100, 16),
+				rawChunk(dataChunkId, make([]byte, 16)),
+			))
+
+			want := broadcast
+			want.CodingHistory = test.want
+
+			for _, loaded := range []*BroadcastChunk{wave.Broadcast, roundTrip(t, wave).Broadcast} {
+				if loaded == nil || *loaded != want {
+					t.Errorf("got %+v, want %+v", loaded, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBroadcastValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk BroadcastChunk
+		want  error
+	}{
+		{"empty", BroadcastChunk{}, nil},
+		{"long description", BroadcastChunk{Description: string(make([]byte, 257))}, ErrBroadcastFieldTooLong},
+		{"date", BroadcastChunk{OriginationDate: "2024/01/02"}, ErrBroadcastDate},
+		{"time", BroadcastChunk{OriginationTime: "3:04:05"}, ErrBroadcastTime},
+		{"umid in version 0", BroadcastChunk{Umid: [64]byte{1}}, ErrBroadcastVersion},
+		{"loudness in version 1", BroadcastChunk{Version: 1, LoudnessValue: -2300}, ErrBroadcastVersion},
+		{"loudness in version 2", BroadcastChunk{Version: 2, LoudnessValue: -2300}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.chunk.Validate(); err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+// files break the spec in ways Validate rejects, they still have to save
+func TestBroadcastOutsideSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk BroadcastChunk
+	}{
+		{"umid in version 0", BroadcastChunk{Umid: [64]byte{1, 2, 3}}},
+		{"loudness in version 1", BroadcastChunk{Version: 1, LoudnessValue: -2300, MaxTruePeakLevel: -100}},
+		{"date separator", BroadcastChunk{Version: 2, OriginationDate: "2020/01/01", OriginationTime: "12h00m00"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.chunk.Validate() == nil {
+				t.Fatal("chunk is valid")
+			}
+
+			wave := loadWave(t, rawStereoWave(rawBroadcast(test.chunk, nil)))
+			loaded := roundTrip(t, wave).Broadcast
+			if loaded == nil || *loaded != test.chunk {
+				t.Errorf("got %+v, want %+v", loaded, test.chunk)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package wave
+
+import (
+	"encoding/binary"
+	"math"
+	"unsafe"
+)
+
+type sampleValue interface {
+	~float32 | ~float64
+}
+
+var nativeLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// decode len(samples) samples, each starting stride bytes after the last
+func (fmt WaveFormat) DecodeSamples(samples []float64, data []byte, stride int) {
+	decodeSamples(fmt, samples, data, stride)
+}
+
+// encode every sample into data, each starting stride bytes after the last
+func (fmt WaveFormat) EncodeSamples(data []byte, stride int, samples []float64) {
+	encodeSamples(fmt, data, stride, samples)
+}
+
+func decodeSamples[T sampleValue](fmt WaveFormat, samples []T, data []byte, stride int) {
+	if len(samples) == 0 {
+		return
+	}
+
+	// bounds check the last sample once, instead of every sample
+	_, byteDepth := fmt.Properties()
+	_ = data[(len(samples)-1)*stride+int(byteDepth)-1]
+
+	switch fmt {
+	case PCM_8:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			samples[n] = T((float64(data[i]) - (1 << 7)) * (1.0 / (1 << 7)))
+		}
+
+	case PCM_16:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			sampleInt := int16(uint16(data[i]) | uint16(data[i+1])<<8)
+			samples[n] = T(float64(sampleInt) * (1.0 / (1 << 15)))
+		}
+
+	case PCM_24:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			// shift into the top of an int32 and back down to sign extend
+			sampleInt := int32(uint32(data[i])<<8|uint32(data[i+1])<<16|uint32(data[i+2])<<24) >> 8
+			samples[n] = T(float64(sampleInt) * (1.0 / (1 << 23)))
+		}
+
+	case PCM_32:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			sampleInt := int32(binary.LittleEndian.Uint32(data[i:]))
+			samples[n] = T(float64(sampleInt) * (1.0 / (1 << 31)))
+		}
+
+	case PCM_FLOAT32:
+		if floats, ok := reinterpretSamples[float32](data, stride, len(samples)); ok {
+			if samples32, ok := any(samples).([]float32); ok {
+				copy(samples32, floats)
+				return
+			}
+
+			for n, sample := range floats {
+				samples[n] = T(sample)
+			}
+			return
+		}
+
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			samples[n] = T(math.Float32frombits(binary.LittleEndian.Uint32(data[i:])))
+		}
+
+	case PCM_FLOAT64:
+		if floats, ok := reinterpretSamples[float64](data, stride, len(samples)); ok {
+			if samples64, ok := any(samples).([]float64); ok {
+				copy(samples64, floats)
+				return
+			}
+
+			for n, sample := range floats {
+				samples[n] = T(sample)
+			}
+			return
+		}
+
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			samples[n] = T(math.Float64frombits(binary.LittleEndian.Uint64(data[i:])))
+		}
+
+	default:
+		panic("Unknown wave format")
+	}
+}
+
+func encodeSamples[T sampleValue](fmt WaveFormat, data []byte, stride int, samples []T) {
+	if len(samples) == 0 {
+		return
+	}
+
+	_, byteDepth := fmt.Properties()
+	_ = data[(len(samples)-1)*stride+int(byteDepth)-1]
+
+	switch fmt {
+	case PCM_8:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			data[i] = uint8(clamp((float64(samples[n])+1)*(1<<7), 0, 255))
+		}
+
+	case PCM_16:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			sampleInt := uint16(int16(clamp(float64(samples[n])*(1<<15), -1<<15, 1<<15-1)))
+			data[i] = byte(sampleInt)
+			data[i+1] = byte(sampleInt >> 8)
+		}
+
+	case PCM_24:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			sampleInt := uint32(int32(clamp(float64(samples[n])*(1<<23), -1<<23, 1<<23-1)))
+			data[i] = byte(sampleInt)
+			data[i+1] = byte(sampleInt >> 8)
+			data[i+2] = byte(sampleInt >> 16)
+		}
+
+	case PCM_32:
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			sampleInt := uint32(int32(clamp(float64(samples[n])*(1<<31), -1<<31, 1<<31-1)))
+			binary.LittleEndian.PutUint32(data[i:], sampleInt)
+		}
+
+	case PCM_FLOAT32:
+		if floats, ok := reinterpretSamples[float32](data, stride, len(samples)); ok {
+			if samples32, ok := any(samples).([]float32); ok {
+				copy(floats, samples32)
+				return
+			}
+
+			for n, sample := range samples {
+				floats[n] = float32(sample)
+			}
+			return
+		}
+
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			binary.LittleEndian.PutUint32(data[i:], math.Float32bits(float32(samples[n])))
+		}
+
+	case PCM_FLOAT64:
+		if floats, ok := reinterpretSamples[float64](data, stride, len(samples)); ok {
+			if samples64, ok := any(samples).([]float64); ok {
+				copy(floats, samples64)
+				return
+			}
+
+			for n, sample := range samples {
+				floats[n] = float64(sample)
+			}
+			return
+		}
+
+		for n, i := 0, 0; n < len(samples); n, i = n+1, i+stride {
+			binary.LittleEndian.PutUint64(data[i:], math.Float64bits(float64(samples[n])))
+		}
+
+	default:
+		panic("Unknown wave format")
+	}
+}
+
+// view packed little endian floats in place, when the host layout allows it
+func reinterpretSamples[F float32 | float64](data []byte, stride int, count int) ([]F, bool) {
+	size := int(unsafe.Sizeof(F(0)))
+
+	if !nativeLittleEndian || stride != size || len(data) < count*size {
+		return nil, false
+	} else if uintptr(unsafe.Pointer(unsafe.SliceData(data)))%uintptr(size) != 0 {
+		return nil, false
+	}
+
+	return unsafe.Slice((*F)(unsafe.Pointer(unsafe.SliceData(data))), count), true
+}
@@ -0,0 +1,140 @@
+package wave
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+var codecFormats = []WaveFormat{PCM_8, PCM_16, PCM_24, PCM_32, PCM_FLOAT32, PCM_FLOAT64}
+
+// benchmarks run over a second of stereo at 48kHz
+const codecFrames = 48000
+
+func formatName(format WaveFormat) string {
+	_, byteDepth := format.Properties()
+	if format.IsFloat() {
+		return fmt.Sprintf("float%d", byteDepth*8)
+	}
+
+	return fmt.Sprintf("pcm%d", byteDepth*8)
+}
+
+// a stereo file of noise filling the range of the format
+func noiseWave(format WaveFormat, frames uint32) *WaveFile {
+	wave := CreateWave(format, 2, 48000)
+	wave.resize(frames)
+
+	buffer := wave.NewPlanarBuffer(frames)
+	var seed uint32 = 1
+	for channel := range buffer {
+		for n := range buffer[channel] {
+			seed = seed*1664525 + 1013904223
+			buffer[channel][n] = float64(seed)/math.MaxUint32*2 - 1
+		}
+	}
+	wave.WriteFrames(0, buffer)
+
+	return wave
+}
+
+// the per-sample path the span loops replaced
+func readFramesPerSample(wave *WaveFile, buffer [][]float64) {
+	_, byteDepth := wave.Fmt.Format.Properties()
+	blockSize := uint32(wave.Fmt.BlockSize())
+	getter := wave.Fmt.Format.SampleGetter()
+
+	for channel, samples := range buffer {
+		index := uint32(byteDepth) * uint32(channel)
+
+		for n := range samples {
+			samples[n] = getter(wave.Data[index : index+uint32(byteDepth)])
+			index += blockSize
+		}
+	}
+}
+
+func writeFramesPerSample(wave *WaveFile, buffer [][]float64) {
+	_, byteDepth := wave.Fmt.Format.Properties()
+	blockSize := uint32(wave.Fmt.BlockSize())
+	setter := wave.Fmt.Format.SampleSetter()
+
+	for channel, samples := range buffer {
+		index := uint32(byteDepth) * uint32(channel)
+
+		for _, sample := range samples {
+			setter(wave.Data[index:index+uint32(byteDepth)], sample)
+			index += blockSize
+		}
+	}
+}
+
+func TestSpansMatchPerSample(t *testing.T) {
+	for _, format := range codecFormats {
+		t.Run(formatName(format), func(t *testing.T) {
+			wave := noiseWave(format, 1000)
+
+			spans := wave.NewPlanarBuffer(1000)
+			perSample := wave.NewPlanarBuffer(1000)
+			wave.ReadFrames(0, spans)
+			readFramesPerSample(wave, perSample)
+
+			for channel := range spans {
+				for n := range spans[channel] {
+					if spans[channel][n] != perSample[channel][n] {
+						t.Fatalf("channel %d frame %d: decoded %v, per sample %v", channel, n, spans[channel][n], perSample[channel][n])
+					}
+				}
+			}
+
+			encoded := noiseWave(format, 1000)
+			writeFramesPerSample(encoded, spans)
+			wave.WriteFrames(0, spans)
+
+			for n := range wave.Data {
+				if wave.Data[n] != encoded.Data[n] {
+					t.Fatalf("byte %d: encoded %d, per sample %d", n, wave.Data[n], encoded.Data[n])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkReadFrames(b *testing.B) {
+	for _, format := range codecFormats {
+		wave := noiseWave(format, codecFrames)
+		buffer := wave.NewPlanarBuffer(codecFrames)
+
+		b.Run(formatName(format)+"/spans", func(b *testing.B) {
+			for b.Loop() {
+				wave.ReadFrames(0, buffer)
+			}
+		})
+
+		b.Run(formatName(format)+"/per-sample", func(b *testing.B) {
+			for b.Loop() {
+				readFramesPerSample(wave, buffer)
+			}
+		})
+	}
+}
+
+func BenchmarkWriteFrames(b *testing.B) {
+	for _, format := range codecFormats {
+		wave := noiseWave(format, codecFrames)
+		buffer := wave.NewPlanarBuffer(codecFrames)
+		wave.ReadFrames(0, buffer)
+
+		b.Run(formatName(format)+"/spans", func(b *testing.B) {
+			for b.Loop() {
+				wave.WriteFrames(0, buffer)
+			}
+		})
+
+		b.Run(formatName(format)+"/per-sample", func(b *testing.B) {
+			for b.Loop() {
+				writeFramesPerSample(wave, buffer)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package wave
+
+// a new file with the format, channel layout and sample rate of target
+func (wave *WaveFile) Convert(target *FmtChunk) (*WaveFile, error) {
+	converted := wave
+
+	if wave.Fmt.Channels != target.Channels || wave.Fmt.ChannelMask != target.ChannelMask {
+		remixed, err := converted.remixTo(target)
+		if err != nil {
+			return nil, err
+		}
+
+		converted = remixed
+	}
+
+	if wave.Fmt.SamplesPerSec != target.SamplesPerSec {
+		resampled, err := converted.Resample(target.SamplesPerSec)
+		if err != nil {
+			return nil, err
+		}
+
+		converted = resampled
+	}
+
+	if wave.Fmt.Format != target.Format {
+		reformatted, err := converted.Reformat(target.Format)
+		if err != nil {
+			return nil, err
+		}
+
+		converted = reformatted
+	}
+
+	if converted == wave {
+		return wave.Copy(0, wave.Frames())
+	}
+
+	return converted, nil
+}
+
+// a new file storing the same samples in another format
+func (wave *WaveFile) Reformat(format WaveFormat) (*WaveFile, error) {
+	reformatted := CreateWave(format, wave.Fmt.Channels, wave.Fmt.SamplesPerSec)
+	reformatted.Fmt.ChannelMask = wave.Fmt.ChannelMask
+	reformatted.resize(wave.Frames())
+
+	buffer := wave.NewPlanarBuffer(wave.Frames())
+
+	_, err := wave.ReadFrames(0, buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	return reformatted, reformatted.WriteFrames(0, buffer)
+}
+
+func (wave *WaveFile) remixTo(target *FmtChunk) (*WaveFile, error) {
+	outputMask := target.ChannelMask
+	if outputMask == 0 {
+		outputMask = defaultChannelMask(target.Channels)
+	}
+
+	matrix, err := LayoutMatrix(wave.Fmt, outputMask)
+	if err != nil {
+		// without known layouts, wrap input channels around the outputs
+		routes := make([]uint16, target.Channels)
+		for n := range routes {
+			routes[n] = uint16(n) % wave.Fmt.Channels
+		}
+
+		matrix, err = RouteMatrix(wave.Fmt.Channels, routes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return wave.Remix(matrix, target.ChannelMask)
+}
+
+// like Convert, but returns the file itself when it already matches target
+func (wave *WaveFile) convertIfNeeded(target *FmtChunk) (*WaveFile, error) {
+	if *wave.Fmt == *target {
+		return wave, nil
+	}
+
+	return wave.Convert(target)
+}
@@ -0,0 +1,75 @@
+package wave
+
+import (
+	"io"
+	"wave-edit/riff"
+)
+
+type CuePoint struct {
+	Id           uint32      // Unique identification value
+	Position     uint32      // Play order position
+	DataChunkId  riff.FourCC // RIFF ID of corresponding data chunk
+	ChunkStart   uint32      // Byte offset of data chunk
+	BlockStart   uint32      // Byte offset of sample of first channel
+	SampleOffset uint32      // Byte offset to sample byte of first channel
+}
+
+type CueChunk []CuePoint
+
+const cueChunkId = "cue "
+
+func cueDeserializer(reader io.Reader, id riff.FourCC, size uint32) (CueChunk, error) {
+	if id != cueChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	}
+
+	count, err := riff.DeserializeDword(reader)
+	if err != nil {
+		return nil, err
+	} else if 4+uint64(count)*24 > uint64(size) {
+		return nil, riff.ErrReadTooMuch
+	}
+
+	points := make(CueChunk, count)
+	for n := range points {
+		points[n], err = riff.DeserializeStruct[CuePoint](reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// skip anything after the points
+	if extra := size - 4 - count*24; extra > 0 {
+		_, err = riff.DeserializeBytes(reader, extra)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return points, nil
+}
+
+func (chunk CueChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, cueChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeDword(writer, uint32(len(chunk)))
+	if err != nil {
+		return err
+	}
+
+	for _, point := range chunk {
+		err = riff.SerializeStruct(writer, point)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (chunk CueChunk) Size() uint32 {
+	return 12 + 24*uint32(len(chunk))
+}
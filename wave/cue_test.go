@@ -0,0 +1,83 @@
+package wave
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func rawCue(extra int, positions ...uint32) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(len(positions)))
+	for n, position := range positions {
+		data = binary.LittleEndian.AppendUint32(data, uint32(n+1))
+		data = binary.LittleEndian.AppendUint32(data, position)
+		data = append(data, dataChunkId...)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, 0)
+		data = binary.LittleEndian.AppendUint32(data, position)
+	}
+
+	return rawChunk(cueChunkId, append(data, make([]byte, extra)...))
+}
+
+func rawLabel(id string, cueId uint32, text string) []byte {
+	return rawChunk(id, append(binary.LittleEndian.AppendUint32(nil, cueId), text...))
+}
+
+func TestCueChunk(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks [][]byte
+		want   Markers
+	}{
+		{
+			"points",
+			[][]byte{rawCue(0, 1, 3)},
+			Markers{{Id: 1, Position: 1}, {Id: 2, Position: 3}},
+		},
+		{
+			"trailing bytes",
+			[][]byte{rawCue(6, 2)},
+			Markers{{Id: 1, Position: 2}},
+		},
+		{
+			"odd trailing bytes",
+			[][]byte{rawCue(3, 2)},
+			Markers{{Id: 1, Position: 2}},
+		},
+		{
+			"labels",
+			[][]byte{rawCue(0, 1), rawList(adtlListType, rawLabel(labelChunkId, 1, "name\x00"), rawLabel(noteChunkId, 1, "note\x00"))},
+			Markers{{Id: 1, Position: 1, Name: "name", Note: "note"}},
+		},
+		{
+			"unterminated and padded labels",
+			[][]byte{rawCue(0, 1), rawList(adtlListType, rawLabel(labelChunkId, 1, "abc"), rawLabel(noteChunkId, 1, "ab\x00\x00\x00\x00"))},
+			Markers{{Id: 1, Position: 1, Name: "abc", Note: "ab"}},
+		},
+		{
+			"region",
+			[][]byte{rawCue(0, 1), rawList(adtlListType, rawChunk(labeledTextChunkId, append(
+				binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 1), 2),
+				append([]byte(regionPurpose), make([]byte, 8)...)...)))},
+			Markers{{Id: 1, Position: 1, Length: 2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawStereoWave(test.chunks...))
+
+			for _, markers := range []Markers{wave.Markers, roundTrip(t, wave).Markers} {
+				if len(markers) != len(test.want) {
+					t.Fatalf("got markers %+v, want %+v", markers, test.want)
+				}
+
+				for n := range markers {
+					if markers[n] != test.want[n] {
+						t.Errorf("got marker %+v, want %+v", markers[n], test.want[n])
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package wave
+
+import "slices"
+
+// replace the frames [start, end) with the frames in data
+// every edit goes through here so positions after the edit stay in sync
+func (wave *WaveFile) spliceFrames(start, end uint32, data []byte) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	blockSize := uint32(wave.Fmt.BlockSize())
+	inserted := uint32(len(data)) / blockSize
+
+	wave.Data = slices.Concat(wave.Data[:start*blockSize], data, wave.Data[end*blockSize:])
+	*wave.Fact = FactChunk(wave.Frames() - (end - start) + inserted)
+	wave.Markers.splice(start, end, inserted)
+
+	if wave.Sampler != nil {
+		wave.Sampler.splice(start, end, inserted)
+	}
+
+	wave.clearPeaks()
+
+	return nil
+}
+
+// peaks no longer describe the audio once it changes, they need computing again
+func (wave *WaveFile) clearPeaks() {
+	wave.Peak = nil
+	wave.Level = nil
+}
+
+// where the start of a range lands after [start, end) was replaced by inserted frames
+// a range starting at the edit stays with the audio that followed it
+func moveStart(position, start, end, inserted uint32) uint32 {
+	if position >= end {
+		return position - (end - start) + inserted
+	} else if position > start {
+		return start + min(position-start, inserted)
+	}
+
+	return position
+}
+
+// where the end of a range lands after [start, end) was replaced by inserted frames
+// a range ending at the edit stays with the audio before it
+func moveEnd(position, start, end, inserted uint32) uint32 {
+	if position > start && position >= end {
+		return position - (end - start) + inserted
+	} else if position > start {
+		return start + min(position-start, inserted)
+	}
+
+	return position
+}
+
+func (wave *WaveFile) frameData(start, end uint32) ([]byte, error) {
+	if end > wave.Frames() {
+		return nil, ErrSampleOutOfRange
+	} else if end < start {
+		return nil, ErrInvalidSampleRange
+	}
+
+	blockSize := uint32(wave.Fmt.BlockSize())
+	return wave.Data[start*blockSize : end*blockSize], nil
+}
+
+func (wave *WaveFile) silence(frames uint32) []byte {
+	data := make([]byte, frames*uint32(wave.Fmt.BlockSize()))
+
+	// unsigned 8-bit samples rest at the middle of their range
+	if wave.Fmt.Format == PCM_8 {
+		for n := range data {
+			data[n] = 1 << 7
+		}
+	}
+
+	return data
+}
+
+func (wave *WaveFile) compatible(other *WaveFile) bool {
+	return wave.Fmt.Format == other.Fmt.Format &&
+		wave.Fmt.Channels == other.Fmt.Channels &&
+		wave.Fmt.SamplesPerSec == other.Fmt.SamplesPerSec
+}
+
+func (wave *WaveFile) Delete(start, end uint32) error {
+	return wave.spliceFrames(start, end, nil)
+}
+
+// keep only the frames [start, end)
+func (wave *WaveFile) Crop(start, end uint32) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	err := wave.spliceFrames(end, wave.Frames(), nil)
+	if err != nil {
+		return err
+	}
+
+	return wave.spliceFrames(0, start, nil)
+}
+
+func (wave *WaveFile) InsertSilence(location, frames uint32) error {
+	return wave.spliceFrames(location, location, wave.silence(frames))
+}
+
+// insert every frame of another file with the same format
+func (wave *WaveFile) Insert(location uint32, other *WaveFile) error {
+	if !wave.compatible(other) {
+		return ErrIncompatibleFormat
+	}
+
+	return wave.spliceFrames(location, location, slices.Clone(other.Data[:other.Frames()*uint32(other.Fmt.BlockSize())]))
+}
+
+// a new file holding a copy of the frames [start, end)
+func (wave *WaveFile) Copy(start, end uint32) (*WaveFile, error) {
+	data, err := wave.frameData(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	clip := CreateWave(wave.Fmt.Format, wave.Fmt.Channels, wave.Fmt.SamplesPerSec)
+	clip.Fmt.ChannelMask = wave.Fmt.ChannelMask
+	clip.Data = slices.Clone(data)
+	*clip.Fact = FactChunk(end - start)
+
+	return clip, nil
+}
+
+// copy the frames [start, end) then delete them
+func (wave *WaveFile) Cut(start, end uint32) (*WaveFile, error) {
+	clip, err := wave.Copy(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return clip, wave.Delete(start, end)
+}
+
+// insert another file, replacing the frames [start, end)
+func (wave *WaveFile) Paste(start, end uint32, clip *WaveFile) error {
+	if !wave.compatible(clip) {
+		return ErrIncompatibleFormat
+	}
+
+	return wave.spliceFrames(start, end, slices.Clone(clip.Data[:clip.Frames()*uint32(clip.Fmt.BlockSize())]))
+}
+
+// repeat the frames [start, end) directly after themselves
+func (wave *WaveFile) Duplicate(start, end uint32) error {
+	data, err := wave.frameData(start, end)
+	if err != nil {
+		return err
+	}
+
+	return wave.spliceFrames(end, end, slices.Clone(data))
+}
@@ -0,0 +1,213 @@
+package wave
+
+import (
+	"fmt"
+	"testing"
+)
+
+// a mono file whose frames hold their own position, with markers and a loop over the region
+func editWave() *WaveFile {
+	wave := CreateWave(PCM_FLOAT64, 1, 44100)
+	wave.resize(10)
+
+	buffer := wave.NewPlanarBuffer(10)
+	for n := range buffer[0] {
+		buffer[0][n] = float64(n)
+	}
+	wave.WriteFrames(0, buffer)
+
+	wave.Markers.AddPoint(2, "point 2")
+	wave.Markers.AddPoint(7, "point 7")
+	region := wave.Markers.AddRegion(3, 6, "region")
+	wave.Markers.AddRegion(8, 10, "tail")
+
+	wave.Sampler = wave.NewSampler()
+	wave.Sampler.Loops = append(wave.Sampler.Loops, LoopFromRegion(*wave.Markers.Find(region)))
+
+	return wave
+}
+
+func clipWave(values ...float64) *WaveFile {
+	clip := CreateWave(PCM_FLOAT64, 1, 44100)
+	clip.resize(uint32(len(values)))
+	clip.WriteFrames(0, [][]float64{values})
+	return clip
+}
+
+// frames as their values, and markers by name as position and length
+func editState(wave *WaveFile) ([]float64, map[string][2]uint32) {
+	buffer := wave.NewPlanarBuffer(wave.Frames())
+	wave.ReadFrames(0, buffer)
+
+	markers := map[string][2]uint32{}
+	for _, marker := range wave.Markers {
+		markers[marker.Name] = [2]uint32{marker.Position, marker.Length}
+	}
+
+	return buffer[0], markers
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(wave *WaveFile) error
+		frames  []float64
+		markers map[string][2]uint32
+		loop    [2]uint32 // first and last frame
+	}{
+		{
+			"delete a region",
+			func(wave *WaveFile) error { return wave.Delete(3, 6) },
+			[]float64{0, 1, 2, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "point 7": {4, 0}, "tail": {5, 2}},
+			[2]uint32{3, 3},
+		},
+		{
+			"delete inside a region",
+			func(wave *WaveFile) error { return wave.Delete(4, 5) },
+			[]float64{0, 1, 2, 3, 5, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 2}, "point 7": {6, 0}, "tail": {7, 2}},
+			[2]uint32{3, 4},
+		},
+		{
+			"delete over the start of a region",
+			func(wave *WaveFile) error { return wave.Delete(1, 4) },
+			[]float64{0, 4, 5, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {1, 0}, "region": {1, 2}, "point 7": {4, 0}, "tail": {5, 2}},
+			[2]uint32{1, 2},
+		},
+		{
+			"crop",
+			func(wave *WaveFile) error { return wave.Crop(2, 8) },
+			[]float64{2, 3, 4, 5, 6, 7},
+			map[string][2]uint32{"point 2": {0, 0}, "region": {1, 3}, "point 7": {5, 0}},
+			[2]uint32{1, 3},
+		},
+		{
+			"insert silence at the start of a region",
+			func(wave *WaveFile) error { return wave.InsertSilence(3, 2) },
+			[]float64{0, 1, 2, 0, 0, 3, 4, 5, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {5, 3}, "point 7": {9, 0}, "tail": {10, 2}},
+			[2]uint32{5, 7},
+		},
+		{
+			"insert at the end of a region",
+			func(wave *WaveFile) error { return wave.Insert(6, clipWave(100, 101)) },
+			[]float64{0, 1, 2, 3, 4, 5, 100, 101, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {9, 0}, "tail": {10, 2}},
+			[2]uint32{3, 5},
+		},
+		{
+			"insert at the end",
+			func(wave *WaveFile) error { return wave.Insert(10, clipWave(100)) },
+			[]float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 100},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {7, 0}, "tail": {8, 2}},
+			[2]uint32{3, 5},
+		},
+		{
+			"paste over part of a region",
+			func(wave *WaveFile) error { return wave.Paste(4, 8, clipWave(100)) },
+			[]float64{0, 1, 2, 3, 100, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 2}, "point 7": {5, 0}, "tail": {5, 2}},
+			[2]uint32{3, 4},
+		},
+		{
+			"paste over a whole region",
+			func(wave *WaveFile) error { return wave.Paste(8, 10, clipWave(100, 101, 102)) },
+			[]float64{0, 1, 2, 3, 4, 5, 6, 7, 100, 101, 102},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {7, 0}, "tail": {8, 3}},
+			[2]uint32{3, 5},
+		},
+		{
+			"duplicate a region",
+			func(wave *WaveFile) error { return wave.Duplicate(3, 6) },
+			[]float64{0, 1, 2, 3, 4, 5, 3, 4, 5, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {10, 0}, "tail": {11, 2}},
+			[2]uint32{3, 5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := editWave()
+
+			err := test.edit(wave)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			frames, markers := editState(wave)
+			if fmt.Sprint(frames) != fmt.Sprint(test.frames) {
+				t.Errorf("got frames %v, want %v", frames, test.frames)
+			}
+
+			if fmt.Sprint(markers) != fmt.Sprint(test.markers) {
+				t.Errorf("got markers %v, want %v", markers, test.markers)
+			}
+
+			loop := wave.Sampler.Loops[0]
+			if [2]uint32{loop.Start, loop.End} != test.loop {
+				t.Errorf("got loop from %d to %d, want %v", loop.Start, loop.End, test.loop)
+			}
+		})
+	}
+}
+
+func TestCopyAndCut(t *testing.T) {
+	wave := editWave()
+
+	clip, err := wave.Copy(3, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames, markers := editState(clip)
+	if fmt.Sprint(frames) != "[3 4 5]" || len(markers) != 0 || wave.Frames() != 10 {
+		t.Errorf("copied %v with markers %v", frames, markers)
+	}
+
+	clip, err = wave.Cut(3, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames, _ = editState(clip)
+	remaining, _ := editState(wave)
+	if fmt.Sprint(frames) != "[3 4 5]" || fmt.Sprint(remaining) != "[0 1 2 6 7 8 9]" {
+		t.Errorf("cut %v leaving %v", frames, remaining)
+	}
+}
+
+func TestEditErrors(t *testing.T) {
+	stereo := CreateWave(PCM_FLOAT64, 2, 44100)
+	stereo.resize(2)
+
+	tests := []struct {
+		name string
+		edit func(wave *WaveFile) error
+		want error
+	}{
+		{"delete backwards", func(wave *WaveFile) error { return wave.Delete(5, 4) }, ErrInvalidSampleRange},
+		{"delete past the end", func(wave *WaveFile) error { return wave.Delete(0, 11) }, ErrSampleOutOfRange},
+		{"crop past the end", func(wave *WaveFile) error { return wave.Crop(0, 11) }, ErrSampleOutOfRange},
+		{"insert past the end", func(wave *WaveFile) error { return wave.InsertSilence(11, 1) }, ErrSampleOutOfRange},
+		{"insert another format", func(wave *WaveFile) error { return wave.Insert(0, stereo) }, ErrIncompatibleFormat},
+		{"paste another format", func(wave *WaveFile) error { return wave.Paste(0, 1, stereo) }, ErrIncompatibleFormat},
+		{"duplicate backwards", func(wave *WaveFile) error { return wave.Duplicate(5, 4) }, ErrInvalidSampleRange},
+		{"copy past the end", func(wave *WaveFile) error { _, err := wave.Copy(9, 11); return err }, ErrSampleOutOfRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := editWave()
+
+			if err := test.edit(wave); err != test.want {
+				t.Errorf("got error %v, want %v", err, test.want)
+			}
+
+			if frames, _ := editState(wave); len(frames) != 10 {
+				t.Errorf("failed edit left %d frames", len(frames))
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package wave
+
+import "math"
+
+type FadeCurve int
+
+const (
+	FADE_LINEAR      FadeCurve = iota
+	FADE_EQUAL_POWER           // Keeps the power of a crossfade between unrelated material steady
+	FADE_LOGARITHMIC           // Rises quickly and eases in to full level
+	FADE_EXPONENTIAL           // Rises slowly, even steps in loudness
+	FADE_S_CURVE               // Eases out of silence and in to full level
+)
+
+// how far above silence the exponential and logarithmic curves start, 40dB
+const fadeCurveRange = 100
+
+// the gain of a fade in, position going from 0 at silence to 1 at full level
+// a fade out is the same curve played backwards
+func (curve FadeCurve) Gain(position float64) float64 {
+	position = max(0, min(position, 1))
+
+	switch curve {
+	case FADE_EQUAL_POWER:
+		return math.Sin(position * math.Pi / 2)
+	case FADE_LOGARITHMIC:
+		return math.Log1p((fadeCurveRange-1)*position) / math.Log(fadeCurveRange)
+	case FADE_EXPONENTIAL:
+		return (math.Pow(fadeCurveRange, position) - 1) / (fadeCurveRange - 1)
+	case FADE_S_CURVE:
+		return (1 - math.Cos(position*math.Pi)) / 2
+	default:
+		return position
+	}
+}
+
+// gain of frame n of a fade in lasting frames, sampled at the middle of each frame
+func (curve FadeCurve) frameGain(n, frames uint32) float64 {
+	return curve.Gain((float64(n) + 0.5) / float64(frames))
+}
+
+// fade the frames [start, end) up from silence
+func (wave *WaveFile) FadeIn(start, end uint32, curve FadeCurve) error {
+	return wave.fade(start, end, func(n, frames uint32) float64 {
+		return curve.frameGain(n, frames)
+	})
+}
+
+// fade the frames [start, end) down to silence
+func (wave *WaveFile) FadeOut(start, end uint32, curve FadeCurve) error {
+	return wave.fade(start, end, func(n, frames uint32) float64 {
+		return curve.frameGain(frames-1-n, frames)
+	})
+}
+
+func (wave *WaveFile) fade(start, end uint32, gain func(n, frames uint32) float64) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	buffer := wave.NewPlanarBuffer(end - start)
+	_, err := wave.ReadFrames(start, buffer)
+	if err != nil {
+		return err
+	}
+
+	for _, samples := range buffer {
+		for n := range samples {
+			samples[n] *= gain(uint32(n), end-start)
+		}
+	}
+
+	return wave.WriteFrames(start, buffer)
+}
+
+// delete the frames [start, end), crossfading over the join instead of cutting
+// the audio at start fades out over length frames as the audio from end fades in
+func (wave *WaveFile) DeleteWithCrossfade(start, end, length uint32, curve FadeCurve) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	// the fade needs audio after the join to fade in
+	length = min(length, wave.Frames()-end)
+
+	outgoing := wave.NewPlanarBuffer(length)
+	incoming := wave.NewPlanarBuffer(length)
+
+	_, err := wave.ReadFrames(start, outgoing)
+	if err != nil {
+		return err
+	}
+
+	_, err = wave.ReadFrames(end, incoming)
+	if err != nil {
+		return err
+	}
+
+	for channel, samples := range incoming {
+		for n := range samples {
+			samples[n] = samples[n]*curve.frameGain(uint32(n), length) +
+				outgoing[channel][n]*curve.frameGain(length-1-uint32(n), length)
+		}
+	}
+
+	err = wave.Delete(start, end)
+	if err != nil {
+		return err
+	}
+
+	return wave.WriteFrames(start, incoming)
+}
@@ -0,0 +1,193 @@
+package wave
+
+import (
+	"math"
+	"testing"
+)
+
+// a mono file holding value in every frame
+func constantWave(frames uint32, value float64) *WaveFile {
+	wave := CreateWave(PCM_FLOAT64, 1, 44100)
+	wave.resize(frames)
+
+	buffer := wave.NewPlanarBuffer(frames)
+	for n := range buffer[0] {
+		buffer[0][n] = value
+	}
+	wave.WriteFrames(0, buffer)
+
+	return wave
+}
+
+func monoFrames(t *testing.T, wave *WaveFile) []float64 {
+	t.Helper()
+
+	buffer := wave.NewPlanarBuffer(wave.Frames())
+	_, err := wave.ReadFrames(0, buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer[0]
+}
+
+func TestFadeCurves(t *testing.T) {
+	curves := []FadeCurve{FADE_LINEAR, FADE_EQUAL_POWER, FADE_LOGARITHMIC, FADE_EXPONENTIAL, FADE_S_CURVE}
+
+	for _, curve := range curves {
+		if curve.Gain(0) != 0 || math.Abs(curve.Gain(1)-1) > 1e-12 {
+			t.Errorf("curve %d: goes from %f to %f", curve, curve.Gain(0), curve.Gain(1))
+		}
+
+		// clamped outside the fade, and always rising
+		if curve.Gain(-1) != curve.Gain(0) || curve.Gain(2) != curve.Gain(1) {
+			t.Errorf("curve %d: not clamped", curve)
+		}
+
+		for n := range 100 {
+			if curve.Gain(float64(n+1)/100) <= curve.Gain(float64(n)/100) {
+				t.Errorf("curve %d: falls at %d%%", curve, n)
+			}
+		}
+	}
+
+	closeTo := func(name string, got, want float64) {
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: got %f, want %f", name, got, want)
+		}
+	}
+
+	// equal power keeps the power of a crossfade steady, linear keeps the sum
+	closeTo("equal power", math.Pow(FADE_EQUAL_POWER.Gain(0.3), 2)+math.Pow(FADE_EQUAL_POWER.Gain(0.7), 2), 1)
+	closeTo("linear", FADE_LINEAR.Gain(0.3)+FADE_LINEAR.Gain(0.7), 1)
+	closeTo("s-curve", FADE_S_CURVE.Gain(0.3)+FADE_S_CURVE.Gain(0.7), 1)
+}
+
+func TestFades(t *testing.T) {
+	tests := []struct {
+		name  string
+		fade  func(wave *WaveFile) error
+		want  []float64
+		error error
+	}{
+		{"in", func(wave *WaveFile) error { return wave.FadeIn(1, 5, FADE_LINEAR) },
+			[]float64{1, 0.125, 0.375, 0.625, 0.875, 1}, nil},
+		{"out", func(wave *WaveFile) error { return wave.FadeOut(2, 6, FADE_LINEAR) },
+			[]float64{1, 1, 0.875, 0.625, 0.375, 0.125}, nil},
+		{"empty", func(wave *WaveFile) error { return wave.FadeIn(3, 3, FADE_LINEAR) },
+			[]float64{1, 1, 1, 1, 1, 1}, nil},
+		{"past the end", func(wave *WaveFile) error { return wave.FadeOut(2, 7, FADE_LINEAR) },
+			[]float64{1, 1, 1, 1, 1, 1}, ErrSampleOutOfRange},
+		{"backwards", func(wave *WaveFile) error { return wave.FadeIn(4, 2, FADE_LINEAR) },
+			[]float64{1, 1, 1, 1, 1, 1}, ErrInvalidSampleRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := constantWave(6, 1)
+
+			err := test.fade(wave)
+			if err != test.error {
+				t.Fatalf("got error %v, want %v", err, test.error)
+			}
+
+			got := monoFrames(t, wave)
+			for n := range test.want {
+				if math.Abs(got[n]-test.want[n]) > 1e-12 {
+					t.Errorf("got %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteWithCrossfade(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  uint32
+		end    uint32
+		length uint32
+		want   []float64
+	}{
+		// frames hold their position, the join mixes the frames at start with those at end
+		{"crossfade", 2, 6, 2, []float64{0, 1, 2*0.75 + 6*0.25, 3*0.25 + 7*0.75, 8, 9}},
+		{"no crossfade", 2, 6, 0, []float64{0, 1, 6, 7, 8, 9}},
+		{"shortened at the end", 2, 8, 4, []float64{0, 1, 2*0.75 + 8*0.25, 3*0.25 + 9*0.75}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := CreateWave(PCM_FLOAT64, 1, 44100)
+			wave.resize(10)
+			buffer := wave.NewPlanarBuffer(10)
+			for n := range buffer[0] {
+				buffer[0][n] = float64(n)
+			}
+			wave.WriteFrames(0, buffer)
+
+			err := wave.DeleteWithCrossfade(test.start, test.end, test.length, FADE_LINEAR)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := monoFrames(t, wave)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+
+			for n := range got {
+				if math.Abs(got[n]-test.want[n]) > 1e-12 {
+					t.Errorf("got %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestConcatenateWithFade(t *testing.T) {
+	tests := []struct {
+		name      string
+		lengths   []uint32
+		crossfade uint32
+		want      []float64
+	}{
+		{"no crossfade", []uint32{2, 3}, 0, []float64{1, 1, 2, 2, 2}},
+		{"crossfade", []uint32{4, 4}, 2, []float64{1, 1, 1*0.75 + 2*0.25, 1*0.25 + 2*0.75, 2, 2}},
+		// a crossfade longer than the pieces fits the shorter of the two
+		{"short last piece", []uint32{4, 1}, 2, []float64{1, 1, 1, 1*0.5 + 2*0.5}},
+		{"short first piece", []uint32{1, 3}, 2, []float64{1*0.5 + 2*0.5, 2, 2}},
+		// a middle piece gives half of itself to each join, so its fades never overlap
+		{"short middle piece", []uint32{4, 3, 4}, 4, []float64{
+			1, 1, 1, 1*0.5 + 2*0.5, 2, 2*0.5 + 3*0.5, 3, 3, 3,
+		}},
+		{"empty middle piece", []uint32{2, 0, 2}, 2, []float64{1, 1, 3, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pieces := make([]*WaveFile, len(test.lengths))
+			for n, length := range test.lengths {
+				pieces[n] = constantWave(length, float64(n+1))
+			}
+
+			joined, err := ConcatenateWithFade(nil, test.crossfade, FADE_LINEAR, pieces...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := monoFrames(t, joined)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+
+			for n := range got {
+				if math.Abs(got[n]-test.want[n]) > 1e-12 {
+					t.Errorf("got %v, want %v", got, test.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package wave
 
 import (
+	"encoding/binary"
 	"errors"
 	"io"
 	"wave-edit/riff"
@@ -10,6 +11,7 @@ type FmtChunk struct {
 	Format        WaveFormat // Format
 	Channels      uint16     // Number of channels
 	SamplesPerSec uint32     // Sampling rate
+	ChannelMask   uint32     // Speaker positions, 0 if unspecified
 }
 
 type rawFmtChunk struct {
@@ -21,21 +23,68 @@ type rawFmtChunk struct {
 	BitsPerSample  uint16 // Sample size
 }
 
+type rawFmtExtension struct {
+	ExtensionSize      uint16 // Size of the extension
+	ValidBitsPerSample uint16 // Bits of precision in a sample
+	ChannelMask        uint32 // Speaker positions
+}
+
 const fmtChunkId = "fmt "
 
+// the tail of every KSDATAFORMAT_SUBTYPE GUID, after the format tag
+var subFormatGuidTail = [14]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
 var ErrUnsupportedFormat = errors.New("unsupported WAVE data format")
 
-func fmtDeserializer(reader io.Reader, id riff.FourCC, _ uint32) (*FmtChunk, error) {
+func fmtDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*FmtChunk, error) {
 	if id != fmtChunkId {
 		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 16 {
+		return nil, riff.ErrUnexpectedEnd
 	}
 
 	rawFmt, err := riff.DeserializeStruct[rawFmtChunk](reader)
 	if err != nil {
 		return nil, err
 	}
+	size -= 16
+
+	formatTag := rawFmt.FormatTag
+	var channelMask uint32
+
+	if formatTag == EXTENSIBLE_FORMAT_TAG {
+		if size < 24 {
+			return nil, riff.ErrUnexpectedEnd
+		}
+
+		extension, err := riff.DeserializeStruct[rawFmtExtension](reader)
+		if err != nil {
+			return nil, err
+		}
+
+		subFormat, err := riff.DeserializeBytes(reader, 16)
+		if err != nil {
+			return nil, err
+		}
+		size -= 24
 
-	format := createWaveFormat(rawFmt.FormatTag, rawFmt.BitsPerSample)
+		if [14]byte(subFormat[2:]) != subFormatGuidTail {
+			return nil, ErrUnsupportedFormat
+		}
+
+		formatTag = binary.LittleEndian.Uint16(subFormat[0:2])
+		channelMask = extension.ChannelMask
+	}
+
+	// skip the rest of the chunk, such as a cbSize of zero
+	if size > 0 {
+		_, err = riff.DeserializeBytes(reader, size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	format := createWaveFormat(formatTag, rawFmt.BitsPerSample)
 
 	if format == UNKNOWN_FORMAT {
 		return nil, ErrUnsupportedFormat
@@ -45,6 +94,7 @@ func fmtDeserializer(reader io.Reader, id riff.FourCC, _ uint32) (*FmtChunk, err
 		Format:        format,
 		Channels:      rawFmt.Channels,
 		SamplesPerSec: rawFmt.SamplesPerSec,
+		ChannelMask:   channelMask,
 	}, nil
 }
 
@@ -66,15 +116,40 @@ func (chunk *FmtChunk) Serialize(writer io.Writer) error {
 		BitsPerSample:  byteDepth * 8,
 	}
 
+	if chunk.isExtensible() {
+		rawFmt.FormatTag = EXTENSIBLE_FORMAT_TAG
+	}
+
 	err = riff.SerializeStruct(writer, rawFmt)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if !chunk.isExtensible() {
+		return nil
+	}
+
+	err = riff.SerializeStruct(writer, rawFmtExtension{
+		ExtensionSize:      22,
+		ValidBitsPerSample: byteDepth * 8,
+		ChannelMask:        chunk.ChannelMask,
+	})
+	if err != nil {
+		return err
+	}
+
+	var subFormat [16]byte
+	binary.LittleEndian.PutUint16(subFormat[0:2], formatTag)
+	copy(subFormat[2:], subFormatGuidTail[:])
+
+	return riff.SerializeBytes(writer, subFormat[:])
 }
 
 func (chunk *FmtChunk) Size() uint32 {
+	if chunk.isExtensible() {
+		return 8 + 40
+	}
+
 	return 8 + 16
 }
 
@@ -82,3 +157,8 @@ func (chunk *FmtChunk) BlockSize() uint16 {
 	_, byteDepth := chunk.Format.Properties()
 	return chunk.Channels * byteDepth
 }
+
+// WAVE_FORMAT_EXTENSIBLE is only needed to carry a speaker layout
+func (chunk *FmtChunk) isExtensible() bool {
+	return chunk.ChannelMask != 0
+}
@@ -0,0 +1,90 @@
+package wave
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// the extension of a WAVE_FORMAT_EXTENSIBLE fmt chunk
+func rawExtension(bitsPerSample uint16, channelMask uint32, formatTag uint16) []byte {
+	data := binary.LittleEndian.AppendUint16(nil, 22)
+	data = binary.LittleEndian.AppendUint16(data, bitsPerSample)
+	data = binary.LittleEndian.AppendUint32(data, channelMask)
+	data = binary.LittleEndian.AppendUint16(data, formatTag)
+	return append(data, subFormatGuidTail[:]...)
+}
+
+func TestFmtChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		fmt  []byte
+		want FmtChunk
+	}{
+		{
+			"pcm",
+			rawFmt(PCM_FORMAT_TAG, 2, 44100, 16),
+			FmtChunk{Format: PCM_16, Channels: 2, SamplesPerSec: 44100},
+		},
+		{
+			"pcm with cbSize of zero",
+			rawFmt(PCM_FORMAT_TAG, 2, 48000, 24, 0, 0),
+			FmtChunk{Format: PCM_24, Channels: 2, SamplesPerSec: 48000},
+		},
+		{
+			"float with cbSize of zero",
+			rawFmt(IEEE_FLOAT_FORMAT_TAG, 2, 44100, 32, 0, 0),
+			FmtChunk{Format: PCM_FLOAT32, Channels: 2, SamplesPerSec: 44100},
+		},
+		{
+			"extensible",
+			rawFmt(EXTENSIBLE_FORMAT_TAG, 6, 48000, 24, rawExtension(24, LAYOUT_5_1, PCM_FORMAT_TAG)...),
+			FmtChunk{Format: PCM_24, Channels: 6, SamplesPerSec: 48000, ChannelMask: LAYOUT_5_1},
+		},
+		{
+			"extensible float",
+			rawFmt(EXTENSIBLE_FORMAT_TAG, 2, 96000, 64, rawExtension(64, LAYOUT_STEREO, IEEE_FLOAT_FORMAT_TAG)...),
+			FmtChunk{Format: PCM_FLOAT64, Channels: 2, SamplesPerSec: 96000, ChannelMask: LAYOUT_STEREO},
+		},
+		{
+			"extensible without a mask",
+			rawFmt(EXTENSIBLE_FORMAT_TAG, 2, 44100, 16, rawExtension(16, 0, PCM_FORMAT_TAG)...),
+			FmtChunk{Format: PCM_16, Channels: 2, SamplesPerSec: 44100},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blockSize := uint32(binary.LittleEndian.Uint16(test.fmt[20:22]))
+			wave := loadWave(t, rawWave(test.fmt, rawChunk(dataChunkId, make([]byte, 4*blockSize)), rawChunk("abcd", nil)))
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				if *loaded.Fmt != test.want {
+					t.Errorf("got %+v, want %+v", *loaded.Fmt, test.want)
+				} else if loaded.Frames() != 4 {
+					t.Errorf("got %d frames, want 4", loaded.Frames())
+				}
+			}
+		})
+	}
+}
+
+func TestUnsupportedFmt(t *testing.T) {
+	tests := []struct {
+		name string
+		fmt  []byte
+	}{
+		{"short", rawChunk(fmtChunkId, make([]byte, 14))},
+		{"unknown format", rawFmt(0x0055, 2, 44100, 16)},
+		{"short extension", rawFmt(EXTENSIBLE_FORMAT_TAG, 2, 44100, 16, 0, 0)},
+		{"unknown sub format", rawFmt(EXTENSIBLE_FORMAT_TAG, 2, 44100, 16, rawExtension(16, 0, 0x0055)...)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := loadWaveError(rawWave(test.fmt, rawChunk(dataChunkId, make([]byte, 16))))
+			if err == nil {
+				t.Error("loaded an unsupported fmt chunk")
+			}
+		})
+	}
+}
@@ -10,6 +10,7 @@ type WaveFormat int
 
 const PCM_FORMAT_TAG = 0x0001
 const IEEE_FLOAT_FORMAT_TAG = 0x0003
+const EXTENSIBLE_FORMAT_TAG = 0xFFFE
 
 const (
 	UNKNOWN_FORMAT WaveFormat = iota
@@ -71,6 +72,12 @@ func (fmt WaveFormat) Properties() (formatTag uint16, byteDepth uint16) {
 	}
 }
 
+// float samples can go past full scale without clipping
+func (fmt WaveFormat) IsFloat() bool {
+	formatTag, _ := fmt.Properties()
+	return formatTag == IEEE_FLOAT_FORMAT_TAG
+}
+
 func (fmt WaveFormat) SampleGetter() func([]byte) float64 {
 	switch fmt {
 	case PCM_8:
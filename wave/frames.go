@@ -0,0 +1,117 @@
+package wave
+
+import "errors"
+
+var ErrBufferChannels = errors.New("buffer channel count does not match file")
+
+// the number of sample frames, each holding one sample per channel
+func (wave *WaveFile) Frames() uint32 {
+	return wave.Fact.Samples()
+}
+
+// a planar buffer with a row for each channel of the file
+func (wave *WaveFile) NewPlanarBuffer(frames uint32) [][]float64 {
+	buffer := make([][]float64, wave.Fmt.Channels)
+	backing := make([]float64, uint32(wave.Fmt.Channels)*frames)
+
+	for channel := range buffer {
+		buffer[channel] = backing[uint32(channel)*frames : uint32(channel+1)*frames]
+	}
+
+	return buffer
+}
+
+// fill a planar buffer with frames from start, returns how many frames were read
+// reads less than the buffer length at the end of the file
+func (wave *WaveFile) ReadFrames(start uint32, buffer [][]float64) (uint32, error) {
+	if len(buffer) != int(wave.Fmt.Channels) {
+		return 0, ErrBufferChannels
+	} else if start > wave.Frames() {
+		return 0, ErrSampleOutOfRange
+	}
+
+	frames := min(planarLength(buffer), wave.Frames()-start)
+	if frames == 0 {
+		return 0, nil
+	}
+
+	for channel, samples := range buffer {
+		wave.decodeChannel(uint16(channel), start, samples[:frames])
+	}
+
+	return frames, nil
+}
+
+// write every frame of a planar buffer starting at start
+func (wave *WaveFile) WriteFrames(start uint32, buffer [][]float64) error {
+	if len(buffer) != int(wave.Fmt.Channels) {
+		return ErrBufferChannels
+	}
+
+	frames := planarLength(buffer)
+	if start > wave.Frames() || frames > wave.Frames()-start {
+		return ErrSampleOutOfRange
+	} else if frames == 0 {
+		return nil
+	}
+
+	for channel, samples := range buffer {
+		wave.encodeChannel(uint16(channel), start, samples[:frames])
+	}
+
+	wave.clearPeaks()
+	return nil
+}
+
+// fill an interleaved buffer with frames from start, returns how many frames were read
+// reads less than the buffer length at the end of the file
+func (wave *WaveFile) ReadInterleaved(start uint32, buffer []float32) (uint32, error) {
+	if start > wave.Frames() {
+		return 0, ErrSampleOutOfRange
+	}
+
+	channels := uint32(wave.Fmt.Channels)
+	frames := min(uint32(len(buffer))/channels, wave.Frames()-start)
+
+	_, byteDepth := wave.Fmt.Format.Properties()
+	index := uint32(wave.Fmt.BlockSize()) * start
+
+	decodeSamples(wave.Fmt.Format, buffer[:frames*channels], wave.Data[index:], int(byteDepth))
+
+	return frames, nil
+}
+
+// write every whole frame of an interleaved buffer starting at start
+func (wave *WaveFile) WriteInterleaved(start uint32, buffer []float32) error {
+	channels := uint32(wave.Fmt.Channels)
+	if uint32(len(buffer))%channels != 0 {
+		return ErrBufferChannels
+	}
+
+	frames := uint32(len(buffer)) / channels
+	if start > wave.Frames() || frames > wave.Frames()-start {
+		return ErrSampleOutOfRange
+	}
+
+	_, byteDepth := wave.Fmt.Format.Properties()
+	index := uint32(wave.Fmt.BlockSize()) * start
+
+	encodeSamples(wave.Fmt.Format, wave.Data[index:], int(byteDepth), buffer)
+
+	wave.clearPeaks()
+	return nil
+}
+
+// the number of frames every channel of a planar buffer can hold
+func planarLength(buffer [][]float64) uint32 {
+	if len(buffer) == 0 {
+		return 0
+	}
+
+	length := len(buffer[0])
+	for _, samples := range buffer[1:] {
+		length = min(length, len(samples))
+	}
+
+	return uint32(length)
+}
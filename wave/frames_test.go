@@ -0,0 +1,103 @@
+package wave
+
+import (
+	"math"
+	"testing"
+)
+
+// a file of frames counting up, a different value for each channel
+func rampWave(format WaveFormat, channels uint16, frames uint32) *WaveFile {
+	wave := CreateWave(format, channels, 44100)
+	wave.resize(frames)
+
+	buffer := wave.NewPlanarBuffer(frames)
+	for channel := range buffer {
+		for n := range buffer[channel] {
+			buffer[channel][n] = float64(n+1)/float64(frames+1)/2 - float64(channel)/16
+		}
+	}
+	wave.WriteFrames(0, buffer)
+
+	return wave
+}
+
+func TestFrameBuffers(t *testing.T) {
+	formats := []WaveFormat{PCM_8, PCM_16, PCM_24, PCM_32, PCM_FLOAT32, PCM_FLOAT64}
+
+	for _, format := range formats {
+		for _, channels := range []uint16{1, 2, 6} {
+			wave := rampWave(format, channels, 10)
+
+			planar := wave.NewPlanarBuffer(10)
+			frames, err := wave.ReadFrames(0, planar)
+			if err != nil || frames != 10 {
+				t.Fatalf("format %d: read %d frames, %v", format, frames, err)
+			}
+
+			interleaved := make([]float32, 10*int(channels))
+			frames, err = wave.ReadInterleaved(0, interleaved)
+			if err != nil || frames != 10 {
+				t.Fatalf("format %d: read %d interleaved frames, %v", format, frames, err)
+			}
+
+			// 8 bit only holds about two decimal places
+			tolerance := 1.0 / 100
+			for channel := range planar {
+				for n, sample := range planar[channel] {
+					want := float64(n+1)/11/2 - float64(channel)/16
+					if math.Abs(sample-want) > tolerance {
+						t.Errorf("format %d channel %d frame %d: got %f, want %f", format, channel, n, sample, want)
+					} else if math.Abs(float64(interleaved[n*int(channels)+channel])-sample) > 1e-6 {
+						t.Errorf("format %d channel %d frame %d: interleaved %f, planar %f",
+							format, channel, n, interleaved[n*int(channels)+channel], sample)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestEmptyFrameRanges(t *testing.T) {
+	wave := rampWave(PCM_16, 2, 8)
+	end := wave.Frames()
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"read at end", func() error {
+			frames, err := wave.ReadFrames(end, wave.NewPlanarBuffer(4))
+			if frames != 0 {
+				t.Errorf("read %d frames past the end", frames)
+			}
+			return err
+		}},
+		{"write at end", func() error { return wave.WriteFrames(end, wave.NewPlanarBuffer(0)) }},
+		{"read interleaved at end", func() error {
+			_, err := wave.ReadInterleaved(end, make([]float32, 8))
+			return err
+		}},
+		{"write interleaved at end", func() error { return wave.WriteInterleaved(end, nil) }},
+		{"reverse", func() error { return wave.Reverse(end, end) }},
+		{"fade", func() error { return wave.FadeIn(end, end, FADE_LINEAR) }},
+		{"varispeed", func() error {
+			return wave.Varispeed(end, end, func(float64) float64 { return 2 })
+		}},
+		{"time stretch", func() error { return wave.TimeStretch(3, 3, 2) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.run(); err != nil {
+				t.Error(err)
+			} else if wave.Frames() != end {
+				t.Errorf("file changed length to %d", wave.Frames())
+			}
+		})
+	}
+
+	_, err := wave.ReadFrames(end+1, wave.NewPlanarBuffer(1))
+	if err != ErrSampleOutOfRange {
+		t.Errorf("read past the end got %v", err)
+	}
+}
@@ -0,0 +1,550 @@
+package wave
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf16"
+	"wave-edit/riff"
+)
+
+type Id3Tag struct {
+	Version  uint8 // ID3v2 major version, 3 or 4
+	Frames   []Id3Frame
+	chunkId  riff.FourCC
+	reserved uint32 // bytes the tag took when read, padded back out to on save
+}
+
+// a frame kept as stored, so frames without accessors survive a save
+type Id3Frame struct {
+	Id    string
+	Flags uint16
+	Data  []byte
+}
+
+type Id3Picture struct {
+	MimeType    string
+	PictureType uint8 // 3 is the front cover
+	Description string
+	Data        []byte
+}
+
+const id3ChunkId = "id3 "
+const id3UpperChunkId = "ID3 "
+
+const (
+	ID3_LATIN1 uint8 = iota
+	ID3_UTF16
+	ID3_UTF16BE
+	ID3_UTF8
+)
+
+const ID3_FRONT_COVER uint8 = 3
+
+// tag flags
+const id3Unsynchronisation = 0x80
+const id3ExtendedHeader = 0x40
+
+// version 4 frame flags
+const id3FrameGrouping = 0x0040
+const id3FrameCompression = 0x0008
+const id3FrameEncryption = 0x0004
+const id3FrameUnsynchronisation = 0x0002
+const id3FrameDataLength = 0x0001
+
+var ErrInvalidId3 = errors.New("invalid ID3 tag")
+var ErrUnsupportedId3 = errors.New("unsupported ID3 version")
+
+// ID3 text frames mirrored into LIST INFO fields
+// the date is TDRC, or TYER from version 3, which SyncId3ToInfo handles itself
+var id3InfoFields = map[string]riff.FourCC{
+	"TIT2": INFO_TITLE,
+	"TPE1": INFO_ARTIST,
+	"TALB": INFO_ALBUM,
+	"TCON": INFO_GENRE,
+	"TRCK": INFO_TRACK,
+	"TCOP": INFO_COPYRIGHT,
+	"TSSE": INFO_SOFTWARE,
+}
+
+func NewId3Tag() *Id3Tag {
+	return &Id3Tag{Version: 4}
+}
+
+func id3Deserializer(reader io.Reader, id riff.FourCC, size uint32) (*Id3Tag, error) {
+	if id != id3ChunkId && id != id3UpperChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	}
+
+	data, err := riff.DeserializeBytes(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := parseId3(data)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.chunkId = id
+	tag.reserved = size
+	return tag, nil
+}
+
+func parseId3(data []byte) (*Id3Tag, error) {
+	if len(data) < 10 || string(data[0:3]) != "ID3" {
+		return nil, ErrInvalidId3
+	}
+
+	version := data[3]
+	flags := data[5]
+	size := syncsafe(data[6:10])
+
+	if version != 3 && version != 4 {
+		return nil, ErrUnsupportedId3
+	} else if 10+int(size) > len(data) {
+		return nil, ErrInvalidId3
+	}
+
+	body := data[10 : 10+size]
+	if flags&id3Unsynchronisation != 0 && version == 3 {
+		body = resynchronise(body)
+	}
+
+	if flags&id3ExtendedHeader != 0 {
+		if len(body) < 4 {
+			return nil, ErrInvalidId3
+		}
+
+		// version 3 doesn't count the size field itself
+		extendedSize := int(binary.BigEndian.Uint32(body))
+		if version == 3 {
+			extendedSize += 4
+		} else {
+			extendedSize = int(syncsafe(body[0:4]))
+		}
+
+		if extendedSize > len(body) {
+			return nil, ErrInvalidId3
+		}
+		body = body[extendedSize:]
+	}
+
+	tag := &Id3Tag{Version: version}
+
+	for len(body) >= 10 && body[0] != 0 {
+		frameSize := binary.BigEndian.Uint32(body[4:8])
+		if version == 4 {
+			frameSize = syncsafe(body[4:8])
+		}
+
+		if 10+int(frameSize) > len(body) {
+			return nil, ErrInvalidId3
+		}
+
+		frame := Id3Frame{
+			Id:    string(body[0:4]),
+			Flags: binary.BigEndian.Uint16(body[8:10]),
+			Data:  slices.Clone(body[10 : 10+frameSize]),
+		}
+		body = body[10+frameSize:]
+
+		if version == 4 {
+			if frame.Flags&id3FrameUnsynchronisation != 0 || flags&id3Unsynchronisation != 0 {
+				frame.Data = resynchronise(frame.Data)
+				frame.Flags &^= id3FrameUnsynchronisation
+			}
+
+			// the length is needed to read compressed or encrypted data, and comes after a group
+			encoded := frame.Flags&(id3FrameGrouping|id3FrameCompression|id3FrameEncryption) != 0
+			if frame.Flags&id3FrameDataLength != 0 && !encoded && len(frame.Data) >= 4 {
+				frame.Data = frame.Data[4:]
+				frame.Flags &^= id3FrameDataLength
+			}
+		}
+
+		tag.Frames = append(tag.Frames, frame)
+	}
+
+	return tag, nil
+}
+
+func (tag *Id3Tag) encode() []byte {
+	var body bytes.Buffer
+
+	for _, frame := range tag.Frames {
+		var header [10]byte
+		copy(header[0:4], frame.Id)
+
+		if tag.Version == 4 {
+			putSyncsafe(header[4:8], uint32(len(frame.Data)))
+		} else {
+			binary.BigEndian.PutUint32(header[4:8], uint32(len(frame.Data)))
+		}
+
+		binary.BigEndian.PutUint16(header[8:10], frame.Flags)
+		body.Write(header[:])
+		body.Write(frame.Data)
+	}
+
+	// keep any padding so other taggers can edit the tag in place
+	if size := 10 + uint32(body.Len()); tag.reserved > size {
+		body.Write(make([]byte, tag.reserved-size))
+	}
+
+	header := [10]byte{'I', 'D', '3', tag.Version, 0, 0}
+	putSyncsafe(header[6:10], uint32(body.Len()))
+
+	return append(header[:], body.Bytes()...)
+}
+
+func (tag *Id3Tag) Serialize(writer io.Writer) error {
+	if tag.Version != 3 && tag.Version != 4 {
+		return ErrUnsupportedId3
+	}
+
+	chunkId := tag.chunkId
+	if chunkId == "" {
+		chunkId = id3ChunkId
+	}
+
+	err := riff.SerializeChunkHeader(writer, chunkId, tag.Size())
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeBytes(writer, tag.encode())
+}
+
+func (tag *Id3Tag) Size() uint32 {
+	return 8 + uint32(len(tag.encode()))
+}
+
+func (tag *Id3Tag) frame(id string) *Id3Frame {
+	for n := range tag.Frames {
+		if tag.Frames[n].Id == id {
+			return &tag.Frames[n]
+		}
+	}
+
+	return nil
+}
+
+func (tag *Id3Tag) setFrame(id string, data []byte, matches func(frame *Id3Frame) bool) {
+	for n := range tag.Frames {
+		if tag.Frames[n].Id == id && matches(&tag.Frames[n]) {
+			tag.Frames[n].Data = data
+			return
+		}
+	}
+
+	tag.Frames = append(tag.Frames, Id3Frame{Id: id, Data: data})
+}
+
+func (tag *Id3Tag) Remove(id string) {
+	tag.Frames = slices.DeleteFunc(tag.Frames, func(frame Id3Frame) bool {
+		return frame.Id == id
+	})
+}
+
+// the values of a text frame such as TIT2, TPE1 or TSRC
+func (tag *Id3Tag) TextValues(id string) []string {
+	frame := tag.frame(id)
+	if frame == nil || len(frame.Data) < 1 || !strings.HasPrefix(id, "T") || id == "TXXX" {
+		return nil
+	}
+
+	return splitId3Strings(frame.Data[0], frame.Data[1:])
+}
+
+// a text frame with multiple values joined by slashes
+func (tag *Id3Tag) Text(id string) string {
+	return strings.Join(tag.TextValues(id), "/")
+}
+
+func (tag *Id3Tag) SetText(id string, values ...string) {
+	if len(values) == 0 {
+		tag.Remove(id)
+		return
+	}
+
+	// version 3 only has one value per frame
+	text := strings.Join(values, "/")
+	if tag.Version == 4 {
+		text = strings.Join(values, "\x00")
+	}
+
+	encoding := tag.textEncoding()
+	data := append([]byte{encoding}, encodeId3String(encoding, text, false)...)
+
+	tag.setFrame(id, data, func(*Id3Frame) bool { return true })
+}
+
+// the value of a TXXX frame with a description
+func (tag *Id3Tag) UserText(description string) string {
+	for _, frame := range tag.Frames {
+		if frame.Id != "TXXX" || len(frame.Data) < 1 {
+			continue
+		}
+
+		values := splitId3Strings(frame.Data[0], frame.Data[1:])
+		if len(values) >= 2 && values[0] == description {
+			return strings.Join(values[1:], "/")
+		}
+	}
+
+	return ""
+}
+
+func (tag *Id3Tag) SetUserText(description, value string) {
+	encoding := tag.textEncoding()
+	data := append([]byte{encoding}, encodeId3String(encoding, description, true)...)
+	data = append(data, encodeId3String(encoding, value, false)...)
+
+	tag.setFrame("TXXX", data, func(frame *Id3Frame) bool {
+		values := splitId3Strings(frame.Data[0], frame.Data[1:])
+		return len(values) > 0 && values[0] == description
+	})
+}
+
+// the text of the first COMM frame
+func (tag *Id3Tag) Comment() string {
+	frame := tag.frame("COMM")
+	if frame == nil || len(frame.Data) < 4 {
+		return ""
+	}
+
+	// skip the language, then the short description
+	values := splitId3Strings(frame.Data[0], frame.Data[4:])
+	if len(values) < 2 {
+		return ""
+	}
+
+	return values[1]
+}
+
+func (tag *Id3Tag) SetComment(text string) {
+	encoding := tag.textEncoding()
+	data := append([]byte{encoding}, "eng"...)
+	data = append(data, encodeId3String(encoding, "", true)...)
+	data = append(data, encodeId3String(encoding, text, false)...)
+
+	tag.setFrame("COMM", data, func(*Id3Frame) bool { return true })
+}
+
+func (tag *Id3Tag) Pictures() []Id3Picture {
+	pictures := []Id3Picture{}
+
+	for _, frame := range tag.Frames {
+		if frame.Id != "APIC" || len(frame.Data) < 1 {
+			continue
+		}
+
+		encoding := frame.Data[0]
+		mimeEnd := bytes.IndexByte(frame.Data[1:], 0)
+		if mimeEnd < 0 || 2+mimeEnd >= len(frame.Data) {
+			continue
+		}
+
+		mimeType := string(frame.Data[1 : 1+mimeEnd])
+		pictureType := frame.Data[2+mimeEnd]
+		description, data, ok := cutId3String(encoding, frame.Data[3+mimeEnd:])
+		if !ok {
+			continue
+		}
+
+		pictures = append(pictures, Id3Picture{
+			MimeType:    mimeType,
+			PictureType: pictureType,
+			Description: description,
+			Data:        data,
+		})
+	}
+
+	return pictures
+}
+
+// add a picture, replacing any with the same picture type
+func (tag *Id3Tag) SetPicture(picture Id3Picture) {
+	encoding := tag.textEncoding()
+	data := append([]byte{encoding}, picture.MimeType...)
+	data = append(data, 0, picture.PictureType)
+	data = append(data, encodeId3String(encoding, picture.Description, true)...)
+	data = append(data, picture.Data...)
+
+	tag.setFrame("APIC", data, func(frame *Id3Frame) bool {
+		mimeEnd := bytes.IndexByte(frame.Data[1:], 0)
+		return mimeEnd >= 0 && 2+mimeEnd < len(frame.Data) && frame.Data[2+mimeEnd] == picture.PictureType
+	})
+}
+
+func (tag *Id3Tag) Title() string  { return tag.Text("TIT2") }
+func (tag *Id3Tag) Artist() string { return tag.Text("TPE1") }
+func (tag *Id3Tag) Album() string  { return tag.Text("TALB") }
+func (tag *Id3Tag) Isrc() string   { return tag.Text("TSRC") }
+
+// version 3 has no UTF-8, so it gets UTF-16
+func (tag *Id3Tag) textEncoding() uint8 {
+	if tag.Version == 4 {
+		return ID3_UTF8
+	}
+
+	return ID3_UTF16
+}
+
+// copy the ID3 fields that LIST INFO has into Info
+func (wave *WaveFile) SyncId3ToInfo() {
+	if wave.Id3 == nil {
+		return
+	}
+
+	if wave.Info == nil {
+		wave.Info = Info{}
+	}
+
+	for frameId, infoId := range id3InfoFields {
+		if text := wave.Id3.Text(frameId); text != "" {
+			wave.Info[infoId] = text
+		}
+	}
+
+	if date := cmp.Or(wave.Id3.Text("TDRC"), wave.Id3.Text("TYER")); date != "" {
+		wave.Info[INFO_DATE] = date
+	}
+
+	if comment := wave.Id3.Comment(); comment != "" {
+		wave.Info[INFO_COMMENT] = comment
+	}
+}
+
+func syncsafe(data []byte) uint32 {
+	return uint32(data[0]&0x7F)<<21 | uint32(data[1]&0x7F)<<14 | uint32(data[2]&0x7F)<<7 | uint32(data[3]&0x7F)
+}
+
+func putSyncsafe(data []byte, value uint32) {
+	data[0] = byte(value>>21) & 0x7F
+	data[1] = byte(value>>14) & 0x7F
+	data[2] = byte(value>>7) & 0x7F
+	data[3] = byte(value) & 0x7F
+}
+
+// undo unsynchronisation, which puts a zero after every 0xFF
+func resynchronise(data []byte) []byte {
+	return bytes.ReplaceAll(data, []byte{0xFF, 0x00}, []byte{0xFF})
+}
+
+func id3Terminator(encoding uint8) []byte {
+	if encoding == ID3_UTF16 || encoding == ID3_UTF16BE {
+		return []byte{0, 0}
+	}
+
+	return []byte{0}
+}
+
+// split data at the first terminated string
+func cutId3String(encoding uint8, data []byte) (string, []byte, bool) {
+	terminator := id3Terminator(encoding)
+
+	for n := 0; n+len(terminator) <= len(data); n += len(terminator) {
+		if bytes.Equal(data[n:n+len(terminator)], terminator) {
+			return decodeId3String(encoding, data[:n]), data[n+len(terminator):], true
+		}
+	}
+
+	return "", nil, false
+}
+
+// every string in data, separated by terminators
+func splitId3Strings(encoding uint8, data []byte) []string {
+	values := []string{}
+
+	for len(data) > 0 {
+		value, rest, ok := cutId3String(encoding, data)
+		if !ok {
+			values = append(values, decodeId3String(encoding, data))
+			break
+		}
+
+		values = append(values, value)
+		data = rest
+	}
+
+	return values
+}
+
+func decodeId3String(encoding uint8, data []byte) string {
+	switch encoding {
+	case ID3_LATIN1:
+		runes := make([]rune, len(data))
+		for n, char := range data {
+			runes[n] = rune(char)
+		}
+		return string(runes)
+
+	case ID3_UTF16, ID3_UTF16BE:
+		order := binary.ByteOrder(binary.BigEndian)
+		if encoding == ID3_UTF16 && len(data) >= 2 {
+			if data[0] == 0xFF && data[1] == 0xFE {
+				order = binary.LittleEndian
+			}
+
+			if (data[0] == 0xFF && data[1] == 0xFE) || (data[0] == 0xFE && data[1] == 0xFF) {
+				data = data[2:]
+			}
+		}
+
+		units := make([]uint16, len(data)/2)
+		for n := range units {
+			units[n] = order.Uint16(data[2*n:])
+		}
+		return string(utf16.Decode(units))
+
+	default:
+		return string(data)
+	}
+}
+
+func encodeId3String(encoding uint8, text string, terminate bool) []byte {
+	var data []byte
+
+	switch encoding {
+	case ID3_UTF16:
+		// every value starts with its own byte order mark
+		for n, value := range strings.Split(text, "\x00") {
+			if n > 0 {
+				data = append(data, 0, 0)
+			}
+
+			data = append(data, 0xFF, 0xFE)
+			for _, unit := range utf16.Encode([]rune(value)) {
+				data = binary.LittleEndian.AppendUint16(data, unit)
+			}
+		}
+
+	case ID3_UTF16BE:
+		for _, unit := range utf16.Encode([]rune(text)) {
+			data = binary.BigEndian.AppendUint16(data, unit)
+		}
+
+	case ID3_LATIN1:
+		for _, char := range text {
+			if char > 0xFF {
+				char = '?'
+			}
+			data = append(data, byte(char))
+		}
+
+	default:
+		data = []byte(text)
+	}
+
+	if terminate {
+		data = append(data, id3Terminator(encoding)...)
+	}
+
+	return data
+}
@@ -0,0 +1,178 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+	"testing"
+)
+
+// an ID3 tag holding a title, padded out with zeros
+func rawId3(version uint8, title string, padding int) []byte {
+	frame := append([]byte{ID3_LATIN1}, title...)
+
+	header := []byte("TIT2")
+	if version == 4 {
+		header = append(header, 0, 0, 0, 0)
+		putSyncsafe(header[4:8], uint32(len(frame)))
+	} else {
+		header = binary.BigEndian.AppendUint32(header, uint32(len(frame)))
+	}
+	header = append(header, 0, 0)
+
+	body := append(append(header, frame...), make([]byte, padding)...)
+
+	tag := []byte{'I', 'D', '3', version, 0, 0, 0, 0, 0, 0}
+	putSyncsafe(tag[6:10], uint32(len(body)))
+	return append(tag, body...)
+}
+
+func TestId3Tag(t *testing.T) {
+	tests := []struct {
+		name    string
+		version uint8
+		padding int
+	}{
+		{"version 3", 3, 0},
+		{"version 4", 4, 0},
+		{"version 3 padded", 3, 1024},
+		{"version 4 padded", 4, 1024},
+		{"odd size", 4, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := rawId3(test.version, "Title", test.padding)
+			wave := loadWave(t, rawStereoWave(rawChunk(id3ChunkId, data)))
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				if loaded.Id3 == nil || loaded.Id3.Title() != "Title" || loaded.Id3.Version != test.version {
+					t.Fatalf("got %+v", loaded.Id3)
+				} else if size := loaded.Id3.Size(); size != 8+uint32(len(data)) {
+					t.Errorf("tag takes %d bytes, read from %d", size, 8+len(data))
+				}
+			}
+
+			// edits use up the padding before growing the tag
+			wave.Id3.SetText("TPE1", "Artist")
+			loaded := roundTrip(t, wave)
+			if loaded.Id3.Artist() != "Artist" || loaded.Id3.Title() != "Title" {
+				t.Errorf("got %+v after an edit", loaded.Id3)
+			} else if test.padding > 100 && loaded.Id3.Size() != 8+uint32(len(data)) {
+				t.Errorf("edit grew the tag from %d to %d bytes", 8+len(data), loaded.Id3.Size())
+			}
+		})
+	}
+}
+
+func TestInfoList(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"terminated", "ab\x00", "ab"},
+		{"unterminated", "ab", "ab"},
+		{"odd unterminated", "abc", "abc"},
+		{"padded", "ab\x00\x00\x00\x00", "ab"},
+		{"empty", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			list := rawList(infoListType, rawChunk(string(INFO_TITLE), []byte(test.text)), rawChunk(string(INFO_ARTIST), []byte("artist\x00")))
+			wave := loadWave(t, rawStereoWave(list))
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				if loaded.Info[INFO_TITLE] != test.want || loaded.Info[INFO_ARTIST] != "artist" {
+					t.Errorf("got %v", loaded.Info)
+				}
+			}
+		})
+	}
+}
+
+// a tag with frames exactly as given
+func rawId3Frames(version uint8, frames ...Id3Frame) []byte {
+	tag := &Id3Tag{Version: version, Frames: frames}
+	return tag.encode()
+}
+
+func id3TextFrame(id, text string) Id3Frame {
+	return Id3Frame{Id: id, Data: append([]byte{ID3_LATIN1}, text...)}
+}
+
+func TestId3DataLength(t *testing.T) {
+	length := []byte{0, 0, 0, 9}
+	compressed := []byte{0x78, 0x9c, 1, 2, 3}
+
+	tests := []struct {
+		name  string
+		frame Id3Frame
+		want  Id3Frame
+	}{
+		{
+			"plain",
+			Id3Frame{Id: "TIT2", Flags: id3FrameDataLength, Data: append(slices.Clone(length), ID3_LATIN1, 'T')},
+			id3TextFrame("TIT2", "T"),
+		},
+		{
+			"compressed",
+			Id3Frame{Id: "APIC", Flags: id3FrameDataLength | id3FrameCompression, Data: append(slices.Clone(length), compressed...)},
+			Id3Frame{Id: "APIC", Flags: id3FrameDataLength | id3FrameCompression, Data: append(slices.Clone(length), compressed...)},
+		},
+		{
+			"encrypted",
+			Id3Frame{Id: "APIC", Flags: id3FrameDataLength | id3FrameEncryption, Data: append([]byte{0x80}, length...)},
+			Id3Frame{Id: "APIC", Flags: id3FrameDataLength | id3FrameEncryption, Data: append([]byte{0x80}, length...)},
+		},
+		{
+			"grouped",
+			Id3Frame{Id: "TIT2", Flags: id3FrameDataLength | id3FrameGrouping, Data: append([]byte{1}, length...)},
+			Id3Frame{Id: "TIT2", Flags: id3FrameDataLength | id3FrameGrouping, Data: append([]byte{1}, length...)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawStereoWave(rawChunk(id3ChunkId, rawId3Frames(4, test.frame))))
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				frames := loaded.Id3.Frames
+				if len(frames) != 1 || frames[0].Id != test.want.Id || frames[0].Flags != test.want.Flags ||
+					!bytes.Equal(frames[0].Data, test.want.Data) {
+					t.Errorf("got %+v, want %+v", frames, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncId3Date(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []Id3Frame
+		want   string
+	}{
+		{"TDRC", []Id3Frame{id3TextFrame("TDRC", "2020-05-01")}, "2020-05-01"},
+		{"TYER", []Id3Frame{id3TextFrame("TYER", "2019")}, "2019"},
+		{"TDRC first", []Id3Frame{id3TextFrame("TDRC", "2020"), id3TextFrame("TYER", "2019")}, "2020"},
+		{"TYER first", []Id3Frame{id3TextFrame("TYER", "2019"), id3TextFrame("TDRC", "2020")}, "2020"},
+		{"neither", []Id3Frame{id3TextFrame("TIT2", "Title")}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// the old map order changed between runs, so try a few
+			for range 20 {
+				wave := loadWave(t, rawStereoWave())
+				wave.Id3 = &Id3Tag{Version: 3, Frames: test.frames}
+				wave.SyncId3ToInfo()
+
+				if wave.Info[INFO_DATE] != test.want {
+					t.Fatalf("got %q, want %q", wave.Info[INFO_DATE], test.want)
+				}
+			}
+		})
+	}
+}
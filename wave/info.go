@@ -0,0 +1,93 @@
+package wave
+
+import (
+	"io"
+	"slices"
+	"wave-edit/riff"
+)
+
+// text fields of a LIST INFO chunk, keyed by chunk ID such as INAM or IART
+type Info map[riff.FourCC]string
+
+// one text field of a LIST INFO chunk
+type InfoTextChunk struct {
+	ChunkId riff.FourCC
+	Text    string
+}
+
+const infoListType = "INFO"
+
+const (
+	INFO_TITLE     riff.FourCC = "INAM"
+	INFO_ARTIST    riff.FourCC = "IART"
+	INFO_ALBUM     riff.FourCC = "IPRD"
+	INFO_COMMENT   riff.FourCC = "ICMT"
+	INFO_DATE      riff.FourCC = "ICRD"
+	INFO_GENRE     riff.FourCC = "IGNR"
+	INFO_TRACK     riff.FourCC = "ITRK"
+	INFO_COPYRIGHT riff.FourCC = "ICOP"
+	INFO_SOFTWARE  riff.FourCC = "ISFT"
+)
+
+func infoDeserializer(reader io.Reader, id riff.FourCC, size uint32) (riff.Chunk, error) {
+	text, err := riff.DeserializeZString(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InfoTextChunk{
+		ChunkId: id,
+		Text:    text,
+	}, nil
+}
+
+func (chunk *InfoTextChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, chunk.ChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeZString(writer, chunk.Text)
+}
+
+func (chunk *InfoTextChunk) Size() uint32 {
+	return 8 + uint32(len(chunk.Text)) + 1
+}
+
+func infoFromList(list *riff.ListChunk[riff.Chunk]) Info {
+	info := Info{}
+
+	for _, chunk := range list.Chunks {
+		if text, ok := chunk.(*InfoTextChunk); ok {
+			info[text.ChunkId] = text.Text
+		}
+	}
+
+	return info
+}
+
+// the LIST INFO chunk to save, nil without any fields
+func (info Info) chunk() *riff.ListChunk[riff.Chunk] {
+	ids := make([]riff.FourCC, 0, len(info))
+	for id, text := range info {
+		if text != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	slices.Sort(ids)
+
+	list := &riff.ListChunk[riff.Chunk]{ListType: infoListType}
+	for _, id := range ids {
+		list.Chunks = append(list.Chunks, &InfoTextChunk{
+			ChunkId: id,
+			Text:    info[id],
+		})
+	}
+
+	return list
+}
@@ -0,0 +1,107 @@
+package wave
+
+import (
+	"errors"
+	"io"
+	"wave-edit/riff"
+)
+
+type InstrumentChunk struct {
+	UnshiftedNote uint8 // MIDI note played at the recorded pitch
+	FineTune      int8  // Pitch adjustment in cents
+	Gain          int8  // Playback gain in dB
+	LowNote       uint8 // Lowest MIDI note to play the sample for
+	HighNote      uint8 // Highest MIDI note to play the sample for
+	LowVelocity   uint8 // Lowest MIDI velocity to play the sample for
+	HighVelocity  uint8 // Highest MIDI velocity to play the sample for
+}
+
+type rawInstrumentChunk struct {
+	UnshiftedNote uint8
+	FineTune      uint8
+	Gain          uint8
+	LowNote       uint8
+	HighNote      uint8
+	LowVelocity   uint8
+	HighVelocity  uint8
+}
+
+const instrumentChunkId = "inst"
+
+var ErrInvalidInstrument = errors.New("instrument note or velocity out of range")
+
+func instrumentDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*InstrumentChunk, error) {
+	if id != instrumentChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 7 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	raw, err := riff.DeserializeStruct[rawInstrumentChunk](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > 7 {
+		_, err = riff.DeserializeBytes(reader, size-7)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &InstrumentChunk{
+		UnshiftedNote: raw.UnshiftedNote,
+		FineTune:      int8(raw.FineTune),
+		Gain:          int8(raw.Gain),
+		LowNote:       raw.LowNote,
+		HighNote:      raw.HighNote,
+		LowVelocity:   raw.LowVelocity,
+		HighVelocity:  raw.HighVelocity,
+	}, nil
+}
+
+func (chunk *InstrumentChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, instrumentChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeStruct(writer, rawInstrumentChunk{
+		UnshiftedNote: chunk.UnshiftedNote,
+		FineTune:      uint8(chunk.FineTune),
+		Gain:          uint8(chunk.Gain),
+		LowNote:       chunk.LowNote,
+		HighNote:      chunk.HighNote,
+		LowVelocity:   chunk.LowVelocity,
+		HighVelocity:  chunk.HighVelocity,
+	})
+}
+
+func (chunk *InstrumentChunk) Size() uint32 {
+	return 8 + 7
+}
+
+// an instrument played at its recorded pitch over every note and velocity
+func NewInstrument(unshiftedNote uint8) *InstrumentChunk {
+	return &InstrumentChunk{
+		UnshiftedNote: unshiftedNote,
+		LowNote:       0,
+		HighNote:      127,
+		LowVelocity:   1,
+		HighVelocity:  127,
+	}
+}
+
+// check notes and velocities are MIDI values, with each range in order
+// Serialize doesn't check, so files read with values outside these still save as they were
+func (chunk *InstrumentChunk) Validate() error {
+	if chunk.UnshiftedNote > 127 || chunk.HighNote > 127 || chunk.HighVelocity > 127 {
+		return ErrInvalidInstrument
+	} else if chunk.FineTune < -50 || chunk.FineTune > 50 {
+		return ErrInvalidInstrument
+	} else if chunk.LowNote > chunk.HighNote || chunk.LowVelocity > chunk.HighVelocity {
+		return ErrInvalidInstrument
+	}
+
+	return nil
+}
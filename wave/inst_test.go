@@ -0,0 +1,76 @@
+package wave
+
+import "testing"
+
+func TestInstrumentChunk(t *testing.T) {
+	instrument := InstrumentChunk{UnshiftedNote: 57, FineTune: -12, Gain: -3, LowNote: 40, HighNote: 70, LowVelocity: 1, HighVelocity: 127}
+	raw := rawStruct(rawInstrumentChunk{57, uint8(0x100 - 12), uint8(0x100 - 3), 40, 70, 1, 127})
+
+	tests := []struct {
+		name  string
+		extra int
+	}{
+		{"odd size", 0},
+		{"reserved byte", 1},
+		{"trailing bytes", 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawStereoWave(rawChunk(instrumentChunkId, append(raw, make([]byte, test.extra)...))))
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				if loaded.Instrument == nil || *loaded.Instrument != instrument {
+					t.Errorf("got %+v, want %+v", loaded.Instrument, instrument)
+				}
+			}
+		})
+	}
+}
+
+func TestInstrumentValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk InstrumentChunk
+		want  error
+	}{
+		{"default", *NewInstrument(60), nil},
+		{"note past 127", InstrumentChunk{UnshiftedNote: 128, HighNote: 127}, ErrInvalidInstrument},
+		{"fine tune past 50 cents", InstrumentChunk{FineTune: 51}, ErrInvalidInstrument},
+		{"notes backwards", InstrumentChunk{LowNote: 70, HighNote: 40}, ErrInvalidInstrument},
+		{"velocities backwards", InstrumentChunk{LowVelocity: 100, HighVelocity: 1}, ErrInvalidInstrument},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.chunk.Validate(); err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentOutsideSpec(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk InstrumentChunk
+	}{
+		{"fine tune past 50 cents", InstrumentChunk{FineTune: -80, HighNote: 127, HighVelocity: 127}},
+		{"notes backwards", InstrumentChunk{LowNote: 70, HighNote: 40, HighVelocity: 127}},
+		{"velocities backwards", InstrumentChunk{HighNote: 127, LowVelocity: 100, HighVelocity: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			chunk := test.chunk
+			raw := rawStruct(rawInstrumentChunk{chunk.UnshiftedNote, uint8(chunk.FineTune), uint8(chunk.Gain),
+				chunk.LowNote, chunk.HighNote, chunk.LowVelocity, chunk.HighVelocity})
+
+			wave := loadWave(t, rawStereoWave(rawChunk(instrumentChunkId, raw)))
+			loaded := roundTrip(t, wave).Instrument
+			if loaded == nil || *loaded != chunk {
+				t.Errorf("got %+v, want %+v", loaded, chunk)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"slices"
+	"wave-edit/riff"
+)
+
+// an element the model doesn't know about, kept as is
+type IxmlElement struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Content []byte     `xml:",innerxml"`
+}
+
+type IxmlChunk struct {
+	XMLName   xml.Name       `xml:"BWFXML"`
+	Version   string         `xml:"IXML_VERSION,omitempty"`
+	Project   string         `xml:"PROJECT,omitempty"`
+	Scene     string         `xml:"SCENE,omitempty"`
+	Take      string         `xml:"TAKE,omitempty"`
+	Tape      string         `xml:"TAPE,omitempty"`
+	Note      string         `xml:"NOTE,omitempty"`
+	Speed     *IxmlSpeed     `xml:"SPEED,omitempty"`
+	TrackList *IxmlTrackList `xml:"TRACK_LIST,omitempty"`
+	Unknown   []IxmlElement  `xml:",any"`
+}
+
+type IxmlSpeed struct {
+	Note                            string        `xml:"NOTE,omitempty"`
+	MasterSpeed                     string        `xml:"MASTER_SPEED,omitempty"`
+	CurrentSpeed                    string        `xml:"CURRENT_SPEED,omitempty"`
+	TimecodeRate                    string        `xml:"TIMECODE_RATE,omitempty"`
+	TimecodeFlag                    string        `xml:"TIMECODE_FLAG,omitempty"`
+	FileSampleRate                  string        `xml:"FILE_SAMPLE_RATE,omitempty"`
+	AudioBitDepth                   string        `xml:"AUDIO_BIT_DEPTH,omitempty"`
+	DigitizerSampleRate             string        `xml:"DIGITIZER_SAMPLE_RATE,omitempty"`
+	TimestampSamplesSinceMidnightHi string        `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_HI,omitempty"`
+	TimestampSamplesSinceMidnightLo string        `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_LO,omitempty"`
+	TimestampSampleRate             string        `xml:"TIMESTAMP_SAMPLE_RATE,omitempty"`
+	Unknown                         []IxmlElement `xml:",any"`
+}
+
+type IxmlTrackList struct {
+	TrackCount int           `xml:"TRACK_COUNT"`
+	Tracks     []IxmlTrack   `xml:"TRACK"`
+	Unknown    []IxmlElement `xml:",any"`
+}
+
+type IxmlTrack struct {
+	ChannelIndex    int           `xml:"CHANNEL_INDEX"`    // Recorder channel, from 1
+	InterleaveIndex int           `xml:"INTERLEAVE_INDEX"` // Channel in this file, from 1
+	Name            string        `xml:"NAME,omitempty"`
+	Function        string        `xml:"FUNCTION,omitempty"`
+	Unknown         []IxmlElement `xml:",any"`
+}
+
+const ixmlChunkId = "iXML"
+
+func ixmlDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*IxmlChunk, error) {
+	if id != ixmlChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	}
+
+	data, err := riff.DeserializeBytes(reader, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// recorders often reserve space with trailing nulls
+	data = bytes.TrimRight(data, "\x00")
+
+	chunk := &IxmlChunk{}
+	err = xml.Unmarshal(data, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+func (chunk *IxmlChunk) encode() ([]byte, error) {
+	data, err := xml.MarshalIndent(chunk, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (chunk *IxmlChunk) Serialize(writer io.Writer) error {
+	data, err := chunk.encode()
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeChunkHeader(writer, ixmlChunkId, 8+uint32(len(data)))
+	if err != nil {
+		return err
+	}
+
+	return riff.SerializeBytes(writer, data)
+}
+
+func (chunk *IxmlChunk) Size() uint32 {
+	// Serialize reports the error when the chunk can't be encoded
+	data, _ := chunk.encode()
+	return 8 + uint32(len(data))
+}
+
+// the track stored in a channel of the file, nil if not listed
+func (chunk *IxmlChunk) Track(channel uint16) *IxmlTrack {
+	if chunk.TrackList == nil {
+		return nil
+	}
+
+	for n := range chunk.TrackList.Tracks {
+		if chunk.TrackList.Tracks[n].InterleaveIndex == int(channel)+1 {
+			return &chunk.TrackList.Tracks[n]
+		}
+	}
+
+	return nil
+}
+
+// set the tracks listed, numbering them in file order
+func (chunk *IxmlChunk) SetTracks(tracks []IxmlTrack) {
+	if chunk.TrackList == nil {
+		chunk.TrackList = &IxmlTrackList{}
+	}
+
+	for n := range tracks {
+		tracks[n].InterleaveIndex = n + 1
+		if tracks[n].ChannelIndex == 0 {
+			tracks[n].ChannelIndex = n + 1
+		}
+	}
+
+	chunk.TrackList.TrackCount = len(tracks)
+	chunk.TrackList.Tracks = tracks
+}
+
+// a deep copy, so changing it leaves the original alone
+func (chunk *IxmlChunk) clone() *IxmlChunk {
+	copied := *chunk
+	copied.Unknown = slices.Clone(chunk.Unknown)
+
+	if chunk.Speed != nil {
+		speed := *chunk.Speed
+		speed.Unknown = slices.Clone(speed.Unknown)
+		copied.Speed = &speed
+	}
+
+	if chunk.TrackList != nil {
+		trackList := *chunk.TrackList
+		trackList.Unknown = slices.Clone(trackList.Unknown)
+		trackList.Tracks = slices.Clone(trackList.Tracks)
+		for n := range trackList.Tracks {
+			trackList.Tracks[n].Unknown = slices.Clone(trackList.Tracks[n].Unknown)
+		}
+		copied.TrackList = &trackList
+	}
+
+	return &copied
+}
+
+// a copy describing only one channel of the file, for splitting
+func (chunk *IxmlChunk) forChannel(channel uint16) *IxmlChunk {
+	single := chunk.clone()
+	single.TrackList = nil
+
+	if track := chunk.Track(channel); track != nil {
+		single.SetTracks([]IxmlTrack{*track})
+	}
+
+	return single
+}
@@ -0,0 +1,84 @@
+package wave
+
+import "testing"
+
+// as a recorder writes it, without indenting and with space reserved after
+const foreignIxml = `<?xml version="1.0" encoding="UTF-8"?><BWFXML><IXML_VERSION>1.61</IXML_VERSION>` +
+	`<PROJECT>film</PROJECT><SCENE>12A</SCENE><TAKE>3</TAKE><SPEED><NOTE>x</NOTE><MASTER_SPEED>25/1</MASTER_SPEED></SPEED>` +
+	`<TRACK_LIST><TRACK_COUNT>2</TRACK_COUNT>` +
+	`<TRACK><CHANNEL_INDEX>1</CHANNEL_INDEX><INTERLEAVE_INDEX>1</INTERLEAVE_INDEX><NAME>Boom</NAME></TRACK>` +
+	`<TRACK><CHANNEL_INDEX>3</CHANNEL_INDEX><INTERLEAVE_INDEX>2</INTERLEAVE_INDEX><NAME>Lav</NAME></TRACK>` +
+	`</TRACK_LIST><BEXT><BWF_ORIGINATOR>recorder</BWF_ORIGINATOR></BEXT></BWFXML>`
+
+func TestIxmlChunk(t *testing.T) {
+	fmtChunk := rawFmt(PCM_FORMAT_TAG, 2, 44100, 16)
+	dataChunk := rawChunk(dataChunkId, make([]byte, 16))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"before data", rawWave(fmtChunk, rawChunk(ixmlChunkId, []byte(foreignIxml)), dataChunk)},
+		{"after data", rawWave(fmtChunk, dataChunk, rawChunk(ixmlChunkId, []byte(foreignIxml)))},
+		{"reserved space", rawStereoWave(rawChunk(ixmlChunkId, append([]byte(foreignIxml), make([]byte, 1001)...)))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, test.data)
+
+			for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+				ixml := loaded.Ixml
+				if ixml == nil {
+					t.Fatal("no iXML chunk")
+				} else if ixml.Project != "film" || ixml.Scene != "12A" || ixml.Take != "3" {
+					t.Errorf("got %+v", ixml)
+				} else if ixml.Speed == nil || ixml.Speed.MasterSpeed != "25/1" {
+					t.Errorf("got speed %+v", ixml.Speed)
+				} else if len(ixml.Unknown) != 1 || ixml.Unknown[0].XMLName.Local != "BEXT" {
+					t.Errorf("lost unknown elements, got %+v", ixml.Unknown)
+				}
+
+				if names := loaded.TrackNames(); len(names) != 2 || names[0] != "Boom" || names[1] != "Lav" {
+					t.Errorf("got track names %v", names)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitAndMergeIxml(t *testing.T) {
+	wave := loadWave(t, rawStereoWave(rawChunk(ixmlChunkId, []byte(foreignIxml))))
+
+	tracks, err := wave.SplitChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n, name := range []string{"Boom", "Lav"} {
+		if names := tracks[n].TrackNames(); len(names) != 1 || names[0] != name {
+			t.Errorf("track %d got names %v, want %s", n, names, name)
+		}
+	}
+
+	merged, err := MergeChannels(tracks, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if track := merged.Ixml.Track(1); track == nil || track.Name != "Lav" || track.ChannelIndex != 3 {
+		t.Errorf("got merged track %+v", track)
+	}
+
+	// merging must leave the inputs alone
+	for n, name := range []string{"Boom", "Lav"} {
+		list := tracks[n].Ixml.TrackList
+		if list.TrackCount != 1 || len(list.Tracks) != 1 || list.Tracks[0].Name != name {
+			t.Errorf("track %d iXML changed to %+v", n, list)
+		}
+	}
+
+	if wave.Ixml.TrackList.TrackCount != 2 {
+		t.Errorf("split changed the original iXML to %+v", wave.Ixml.TrackList)
+	}
+}
@@ -0,0 +1,131 @@
+package wave
+
+import (
+	"slices"
+	"wave-edit/riff"
+)
+
+type ChunkOrder int
+
+const (
+	ORDER_DEFAULT        ChunkOrder = iota // bext and peaks before the audio, other metadata after
+	ORDER_ORIGINAL                         // the order the chunks were read in
+	ORDER_METADATA_FIRST                   // every metadata chunk before the audio
+	ORDER_METADATA_LAST                    // every metadata chunk after the audio
+)
+
+// a JUNK chunk read from a file is only kept in the original order, other orders drop it
+type ChunkLayout struct {
+	Order         ChunkOrder
+	ReserveJunk   bool   // Reserve room for a ds64 chunk, so the file can become RF64 in place
+	DataAlignment uint32 // Start the samples on a multiple of this many bytes, 0 for anywhere
+	original      []string
+	junk          *riff.FillerChunk // the first JUNK chunk read
+}
+
+// a chunk with the key its position is remembered by
+type layoutChunk struct {
+	key   string
+	chunk riff.Chunk
+}
+
+// data size of the ds64 chunk a JUNK chunk reserves room for
+const ds64Size = 28
+
+// the key of a chunk read from a file, lists are told apart by type
+func layoutKey(id riff.FourCC, listType riff.FourCC) string {
+	if id == riff.ListChunkId {
+		return string(id) + " " + string(listType)
+	}
+
+	return string(id)
+}
+
+func isAudioKey(key string) bool {
+	return key == fmtChunkId || key == factChunkId || key == dataChunkId
+}
+
+// order chunks by the layout, adding JUNK and PAD chunks
+func (layout *ChunkLayout) arrange(chunks []layoutChunk) []riff.Chunk {
+	switch layout.Order {
+	case ORDER_ORIGINAL:
+		// new chunks follow the chunk before them in the default order
+		positions := map[string]int{}
+		last := -1
+		for _, entry := range chunks {
+			if index := slices.Index(layout.original, entry.key); index >= 0 {
+				positions[entry.key] = 2 * index
+				last = 2 * index
+			} else {
+				positions[entry.key] = last + 1
+			}
+		}
+
+		slices.SortStableFunc(chunks, func(a, b layoutChunk) int {
+			return positions[a.key] - positions[b.key]
+		})
+
+	case ORDER_METADATA_FIRST, ORDER_METADATA_LAST:
+		rank := func(key string) int {
+			switch {
+			case key == dataChunkId:
+				return 2
+			case isAudioKey(key):
+				return 0
+			case layout.Order == ORDER_METADATA_FIRST:
+				return 1
+			default:
+				return 3
+			}
+		}
+
+		slices.SortStableFunc(chunks, func(a, b layoutChunk) int {
+			return rank(a.key) - rank(b.key)
+		})
+	}
+
+	arranged := []riff.Chunk{}
+
+	// ds64 has to be the first chunk of an RF64 file
+	if layout.ReserveJunk {
+		arranged = append(arranged, &riff.FillerChunk{ChunkId: riff.JunkChunkId, DataSize: ds64Size})
+	}
+
+	// the RIFF header comes before every chunk
+	var offset uint32 = 12
+	for _, chunk := range arranged {
+		offset += riff.PaddedSize(chunk)
+	}
+
+	for _, entry := range chunks {
+		if entry.key == dataChunkId && layout.DataAlignment > 1 {
+			if pad := layout.padding(offset); pad != nil {
+				arranged = append(arranged, pad)
+				offset += riff.PaddedSize(pad)
+			}
+		}
+
+		arranged = append(arranged, entry.chunk)
+		offset += riff.PaddedSize(entry.chunk)
+	}
+
+	return arranged
+}
+
+// a PAD chunk moving samples after a data header at offset onto the alignment
+func (layout *ChunkLayout) padding(offset uint32) *riff.FillerChunk {
+	alignment := layout.DataAlignment
+
+	// samples start after the data chunk header
+	needed := (alignment - (offset+8)%alignment) % alignment
+	if needed == 0 {
+		return nil
+	}
+
+	// the pad needs room for its own header, and chunks have even sizes
+	for needed < 8 || needed%2 == 1 {
+		needed += alignment
+	}
+
+	return &riff.FillerChunk{ChunkId: riff.PadChunkId, DataSize: needed - 8}
+}
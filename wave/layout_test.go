@@ -0,0 +1,124 @@
+package wave
+
+import (
+	"encoding/binary"
+	"reflect"
+	"slices"
+	"testing"
+	"wave-edit/riff"
+)
+
+// the key of each chunk in a saved file, and where its data starts
+func chunkLayout(data []byte) ([]string, []int) {
+	keys := []string{}
+	offsets := []int{}
+
+	for at := 12; at+8 <= len(data); {
+		id := riff.FourCC(data[at : at+4])
+		size := int(binary.LittleEndian.Uint32(data[at+4:]))
+
+		var listType riff.FourCC
+		if id == riff.ListChunkId {
+			listType = riff.FourCC(data[at+8 : at+12])
+		}
+
+		keys = append(keys, layoutKey(id, listType))
+		offsets = append(offsets, at+8)
+		at += 8 + size + size%2
+	}
+
+	return keys, offsets
+}
+
+func TestChunkOrder(t *testing.T) {
+	original := rawWave(
+		rawChunk(acidChunkId, rawStruct(*NewAcid(120, 4))),
+		rawFmt(PCM_FORMAT_TAG, 2, 44100, 16),
+		rawList(infoListType, rawChunk(string(INFO_TITLE), []byte("title\x00"))),
+		rawChunk(dataChunkId, make([]byte, 16)),
+		rawCue(0, 1),
+	)
+
+	tests := []struct {
+		order ChunkOrder
+		want  []string
+	}{
+		{ORDER_DEFAULT, []string{"fmt ", "fact", "data", "acid", "LIST INFO", "cue "}},
+		{ORDER_ORIGINAL, []string{"acid", "fmt ", "fact", "LIST INFO", "data", "cue "}},
+		{ORDER_METADATA_FIRST, []string{"fmt ", "fact", "acid", "LIST INFO", "cue ", "data"}},
+		{ORDER_METADATA_LAST, []string{"fmt ", "fact", "data", "acid", "LIST INFO", "cue "}},
+	}
+
+	for _, test := range tests {
+		wave := loadWave(t, original)
+		wave.Layout.Order = test.order
+
+		if keys, _ := chunkLayout(saveWave(t, wave)); !reflect.DeepEqual(keys, test.want) {
+			t.Errorf("order %d: got %v, want %v", test.order, keys, test.want)
+		}
+	}
+}
+
+func TestChunkReservation(t *testing.T) {
+	for _, alignment := range []uint32{0, 2, 512, 4096} {
+		wave := loadWave(t, rawStereoWave(rawChunk("abcd", []byte{1})))
+		wave.Layout.ReserveJunk = true
+		wave.Layout.DataAlignment = alignment
+
+		keys, offsets := chunkLayout(saveWave(t, wave))
+		if keys[0] != riff.JunkChunkId {
+			t.Errorf("alignment %d: got %v, want JUNK first", alignment, keys)
+		}
+
+		data := offsets[slices.Index(keys, dataChunkId)]
+		if alignment > 1 && data%int(alignment) != 0 {
+			t.Errorf("alignment %d: samples start at %d", alignment, data)
+		}
+
+		if loaded := roundTrip(t, wave); loaded.Frames() != 4 {
+			t.Errorf("alignment %d: got %d frames", alignment, loaded.Frames())
+		}
+	}
+}
+
+func TestJunkFromFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		junk    []byte
+		order   ChunkOrder
+		reserve bool
+		want    []string
+	}{
+		{"original order", make([]byte, ds64Size), ORDER_ORIGINAL, false, []string{"JUNK", "fmt ", "fact", "data", "acid"}},
+		{"odd size", make([]byte, 5), ORDER_ORIGINAL, false, []string{"JUNK", "fmt ", "fact", "data", "acid"}},
+		{"default order", make([]byte, ds64Size), ORDER_DEFAULT, false, []string{"fmt ", "fact", "data", "acid"}},
+		{"metadata first", make([]byte, ds64Size), ORDER_METADATA_FIRST, false, []string{"fmt ", "fact", "acid", "data"}},
+		{"new reservation", make([]byte, ds64Size), ORDER_ORIGINAL, true, []string{"JUNK", "fmt ", "fact", "data", "acid"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawWave(
+				rawChunk(riff.JunkChunkId, test.junk),
+				rawFmt(PCM_FORMAT_TAG, 2, 44100, 16),
+				rawChunk(dataChunkId, make([]byte, 16)),
+				rawChunk(acidChunkId, rawStruct(*NewAcid(120, 4))),
+			))
+			wave.Layout.Order = test.order
+			wave.Layout.ReserveJunk = test.reserve
+
+			saved := saveWave(t, wave)
+			keys, offsets := chunkLayout(saved)
+			if !reflect.DeepEqual(keys, test.want) {
+				t.Fatalf("got %v, want %v", keys, test.want)
+			}
+
+			// a kept JUNK chunk has the size it was read with
+			if keys[0] == riff.JunkChunkId && !test.reserve {
+				if size := binary.LittleEndian.Uint32(saved[offsets[0]-4:]); size != uint32(len(test.junk)) {
+					t.Errorf("got JUNK of %d bytes, want %d", size, len(test.junk))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package wave
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+	"wave-edit/riff"
+)
+
+// an EBU Tech 3285 supplement 3 peak envelope
+type LevelChunk struct {
+	Version        uint32   // Always 1
+	Format         uint32   // LEVEL_8_BIT or LEVEL_16_BIT
+	PointsPerValue uint32   // 1 for the absolute peak, 2 for positive then negative peaks
+	BlockSize      uint32   // Frames summarised by each peak frame
+	Channels       uint32   // Channels in each peak frame
+	PeakOfPeaks    uint32   // Frame of the largest sample in the file
+	Timestamp      string   // Creation time as YYYY:MM:DD:hh:mm:ss:uuu
+	Points         []uint16 // Peak points by frame, then channel, then point
+}
+
+type rawLevelChunk struct {
+	Version        uint32
+	Format         uint32
+	PointsPerValue uint32
+	BlockSize      uint32
+	PeakChannels   uint32
+	NumPeakFrames  uint32
+	PosPeakOfPeaks uint32
+	OffsetToPeaks  uint32
+}
+
+const levelChunkId = "levl"
+
+// size of the header, before the reserved space ends
+const levelHeaderSize = 120
+
+// where the peaks start, the spec counts OffsetToPeaks from the chunk ID
+const levelPeaksOffset = 8 + levelHeaderSize
+
+const (
+	LEVEL_8_BIT  uint32 = 1
+	LEVEL_16_BIT uint32 = 2
+)
+
+const DEFAULT_LEVEL_BLOCK_SIZE = 256
+
+var ErrInvalidLevel = errors.New("invalid peak envelope settings")
+
+func levelDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*LevelChunk, error) {
+	if id != levelChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < levelHeaderSize {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	raw, err := riff.DeserializeStruct[rawLevelChunk](reader)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := riff.DeserializeZString(reader, 28)
+	if err != nil {
+		return nil, err
+	}
+
+	if raw.Format != LEVEL_8_BIT && raw.Format != LEVEL_16_BIT {
+		return nil, ErrInvalidLevel
+	} else if raw.OffsetToPeaks < levelPeaksOffset || raw.OffsetToPeaks-8 > size {
+		return nil, riff.ErrReadTooMuch
+	}
+
+	// reserved space, and anything else before the peaks
+	_, err = riff.DeserializeBytes(reader, raw.OffsetToPeaks-levelPeaksOffset+60)
+	if err != nil {
+		return nil, err
+	}
+
+	peaksStart := raw.OffsetToPeaks - 8
+	pointSize := raw.Format
+	pointCount := uint64(raw.NumPeakFrames) * uint64(raw.PeakChannels) * uint64(raw.PointsPerValue)
+	if uint64(peaksStart)+pointCount*uint64(pointSize) > uint64(size) {
+		return nil, riff.ErrReadTooMuch
+	}
+
+	data, err := riff.DeserializeBytes(reader, size-peaksStart)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]uint16, pointCount)
+	for n := range points {
+		if pointSize == 1 {
+			points[n] = uint16(data[n])
+		} else {
+			points[n] = uint16(data[2*n]) | uint16(data[2*n+1])<<8
+		}
+	}
+
+	return &LevelChunk{
+		Version:        raw.Version,
+		Format:         raw.Format,
+		PointsPerValue: raw.PointsPerValue,
+		BlockSize:      raw.BlockSize,
+		Channels:       raw.PeakChannels,
+		PeakOfPeaks:    raw.PosPeakOfPeaks,
+		Timestamp:      timestamp,
+		Points:         points,
+	}, nil
+}
+
+func (chunk *LevelChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, levelChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeStruct(writer, rawLevelChunk{
+		Version:        chunk.Version,
+		Format:         chunk.Format,
+		PointsPerValue: chunk.PointsPerValue,
+		BlockSize:      chunk.BlockSize,
+		PeakChannels:   chunk.Channels,
+		NumPeakFrames:  chunk.peakFrames(),
+		PosPeakOfPeaks: chunk.PeakOfPeaks,
+		OffsetToPeaks:  levelPeaksOffset,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeFixedString(writer, chunk.Timestamp, 28)
+	if err != nil {
+		return err
+	}
+
+	// reserved
+	err = riff.SerializeBytes(writer, make([]byte, 60))
+	if err != nil {
+		return err
+	}
+
+	data := make([]byte, 0, uint32(len(chunk.Points))*chunk.Format)
+	for _, point := range chunk.Points {
+		if chunk.Format == LEVEL_8_BIT {
+			data = append(data, uint8(point))
+		} else {
+			data = append(data, uint8(point), uint8(point>>8))
+		}
+	}
+
+	return riff.SerializeBytes(writer, data)
+}
+
+func (chunk *LevelChunk) Size() uint32 {
+	return 8 + levelHeaderSize + uint32(len(chunk.Points))*chunk.Format
+}
+
+func (chunk *LevelChunk) peakFrames() uint32 {
+	if chunk.Channels == 0 || chunk.PointsPerValue == 0 {
+		return 0
+	}
+
+	return uint32(len(chunk.Points)) / (chunk.Channels * chunk.PointsPerValue)
+}
+
+// the largest point value, standing for a full scale sample
+func (chunk *LevelChunk) fullScale() float64 {
+	if chunk.Format == LEVEL_8_BIT {
+		return math.MaxUint8
+	}
+
+	return math.MaxUint16
+}
+
+// summarise every blockSize frames into a peak frame, setting the levl chunk
+func (wave *WaveFile) ComputeLevels(format, pointsPerValue, blockSize uint32) (*LevelChunk, error) {
+	if format != LEVEL_8_BIT && format != LEVEL_16_BIT {
+		return nil, ErrInvalidLevel
+	} else if pointsPerValue != 1 && pointsPerValue != 2 {
+		return nil, ErrInvalidLevel
+	} else if blockSize == 0 {
+		return nil, ErrInvalidLevel
+	}
+
+	channels := uint32(wave.Fmt.Channels)
+	peakFrames := (wave.Frames() + blockSize - 1) / blockSize
+	now := time.Now()
+
+	chunk := &LevelChunk{
+		Version:        1,
+		Format:         format,
+		PointsPerValue: pointsPerValue,
+		BlockSize:      blockSize,
+		Channels:       channels,
+		Timestamp:      now.Format("2006:01:02:15:04:05") + fmt.Sprintf(":%03d", now.Nanosecond()/1e6),
+		Points:         make([]uint16, peakFrames*channels*pointsPerValue),
+	}
+
+	fullScale := chunk.fullScale()
+	var largest float64
+	buffer := wave.NewPlanarBuffer(blockSize)
+
+	for peakFrame := range peakFrames {
+		start := peakFrame * blockSize
+		frames, err := wave.ReadFrames(start, buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		for channel, samples := range buffer {
+			var positive, negative float64
+
+			for n, sample := range samples[:frames] {
+				positive = max(positive, sample)
+				negative = max(negative, -sample)
+
+				if math.Abs(sample) > largest {
+					largest = math.Abs(sample)
+					chunk.PeakOfPeaks = start + uint32(n)
+				}
+			}
+
+			index := (peakFrame*channels + uint32(channel)) * pointsPerValue
+			if pointsPerValue == 1 {
+				chunk.Points[index] = uint16(clamp(max(positive, negative), 0, 1) * fullScale)
+			} else {
+				chunk.Points[index] = uint16(clamp(positive, 0, 1) * fullScale)
+				chunk.Points[index+1] = uint16(clamp(negative, 0, 1) * fullScale)
+			}
+		}
+	}
+
+	wave.Level = chunk
+	return chunk, nil
+}
+
+// positive and negative peaks of a channel for each of width columns, in [-1, 1]
+// uses the levl chunk when it matches the file, otherwise reads every sample
+func (wave *WaveFile) Overview(channel uint16, width int) (positive, negative []float64, err error) {
+	if channel >= wave.Fmt.Channels {
+		return nil, nil, ErrChannelDoesNotExist
+	}
+
+	positive = make([]float64, width)
+	negative = make([]float64, width)
+
+	if width == 0 || wave.Frames() == 0 {
+		return positive, negative, nil
+	}
+
+	column := func(frame uint32) int {
+		return int(uint64(frame) * uint64(width) / uint64(wave.Frames()))
+	}
+
+	level := wave.Level
+	if level != nil && level.Channels == uint32(wave.Fmt.Channels) && level.BlockSize > 0 &&
+		level.peakFrames() == (wave.Frames()+level.BlockSize-1)/level.BlockSize {
+		fullScale := level.fullScale()
+
+		for peakFrame := range level.peakFrames() {
+			index := (peakFrame*level.Channels + uint32(channel)) * level.PointsPerValue
+			up := float64(level.Points[index]) / fullScale
+			down := up
+			if level.PointsPerValue == 2 {
+				down = float64(level.Points[index+1]) / fullScale
+			}
+
+			n := min(column(peakFrame*level.BlockSize), width-1)
+			positive[n] = max(positive[n], up)
+			negative[n] = min(negative[n], -down)
+		}
+
+		return positive, negative, nil
+	}
+
+	buffer := wave.NewPlanarBuffer(min(peakScanFrames, wave.Frames()))
+	for start := uint32(0); start < wave.Frames(); start += peakScanFrames {
+		frames, err := wave.ReadFrames(start, buffer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for n, sample := range buffer[channel][:frames] {
+			x := column(start + uint32(n))
+			positive[x] = max(positive[x], sample)
+			negative[x] = min(negative[x], sample)
+		}
+	}
+
+	return positive, negative, nil
+}
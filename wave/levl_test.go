@@ -0,0 +1,127 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// a stereo levl chunk as the spec lays it out, with gap bytes between the header and the peaks
+func rawLevel(points []uint16, gap int) []byte {
+	data := rawStruct(rawLevelChunk{
+		Version:        1,
+		Format:         LEVEL_16_BIT,
+		PointsPerValue: 2,
+		BlockSize:      2,
+		PeakChannels:   2,
+		NumPeakFrames:  uint32(len(points) / 4),
+		OffsetToPeaks:  128 + uint32(gap),
+	})
+
+	data = append(data, "2024:01:02:03:04:05:678"...)
+	data = append(data, make([]byte, 28-23+60+gap)...)
+	for _, point := range points {
+		data = binary.LittleEndian.AppendUint16(data, point)
+	}
+
+	return rawChunk(levelChunkId, data)
+}
+
+func TestLevelChunk(t *testing.T) {
+	points := []uint16{100, 200, 300, 400, 500, 600, 700, 800}
+
+	for _, gap := range []int{0, 4} {
+		wave := loadWave(t, rawStereoWave(rawLevel(points, gap)))
+
+		for _, loaded := range []*WaveFile{wave, roundTrip(t, wave)} {
+			level := loaded.Level
+			if level == nil || !reflect.DeepEqual(level.Points, points) || level.Timestamp != "2024:01:02:03:04:05:678" {
+				t.Fatalf("gap %d: got %+v", gap, level)
+			}
+		}
+	}
+
+	// saved peaks start where the spec says
+	data := saveWave(t, loadWave(t, rawStereoWave(rawLevel(points, 0))))
+	at := bytes.Index(data, []byte(levelChunkId))
+	if offset := binary.LittleEndian.Uint32(data[at+8+28:]); offset != 128 {
+		t.Errorf("saved OffsetToPeaks %d, want 128", offset)
+	} else if !bytes.Equal(data[at+128:at+128+16], rawLevel(points, 0)[128:]) {
+		t.Errorf("saved peaks %v", data[at+128:at+128+16])
+	}
+}
+
+func TestLevelOverview(t *testing.T) {
+	wave := rampWave(PCM_16, 2, 1000)
+	scanned, _, err := wave.Overview(1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = wave.ComputeLevels(LEVEL_16_BIT, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// levels are only as fine as a 16 bit point
+	fromLevels, _, _ := wave.Overview(1, 10)
+	for n := range scanned {
+		if diff := scanned[n] - fromLevels[n]; diff > 1e-4 || diff < -1e-4 {
+			t.Errorf("column %d: scanned %f, from levels %f", n, scanned[n], fromLevels[n])
+		}
+	}
+
+	// a levl chunk for a different length is ignored
+	wave.Level.Points = wave.Level.Points[:len(wave.Level.Points)/2]
+	stale, _, _ := wave.Overview(1, 10)
+	if !reflect.DeepEqual(stale, scanned) {
+		t.Errorf("used levels of the wrong length, got %v, want %v", stale, scanned)
+	}
+}
+
+func TestWritesClearPeaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(wave *WaveFile) error
+	}{
+		{"write frames", func(wave *WaveFile) error { return wave.WriteFrames(0, wave.NewPlanarBuffer(1)) }},
+		{"write interleaved", func(wave *WaveFile) error { return wave.WriteInterleaved(0, make([]float32, 2)) }},
+		{"set samples", func(wave *WaveFile) error { return wave.SetSamples(1, 0, []float64{0}) }},
+		{"fade", func(wave *WaveFile) error { return wave.FadeOut(0, 10, FADE_LINEAR) }},
+		{"reverse", func(wave *WaveFile) error { return wave.Reverse(0, 10) }},
+		{"delete", func(wave *WaveFile) error { return wave.Delete(0, 10) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := rampWave(PCM_16, 2, 100)
+			wave.ComputePeaks()
+			wave.ComputeLevels(LEVEL_8_BIT, 1, 10)
+
+			if err := test.write(wave); err != nil {
+				t.Fatal(err)
+			} else if wave.Peak != nil || wave.Level != nil {
+				t.Error("peaks kept after the audio changed")
+			}
+		})
+	}
+}
+
+func TestPeakChunk(t *testing.T) {
+	wave := rampWave(PCM_FLOAT32, 2, 100)
+	peak, err := wave.ComputePeaks()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the ramp rises to its last frame, the second channel sits lower
+	if peak.Peaks[0].Position != 99 || peak.Peaks[1].Position != 99 || peak.Peaks[0].Value <= peak.Peaks[1].Value {
+		t.Errorf("got %+v", peak.Peaks)
+	}
+
+	loaded := roundTrip(t, wave)
+	if !reflect.DeepEqual(loaded.Peak, peak) {
+		t.Errorf("got %+v, want %+v", loaded.Peak, peak)
+	}
+}
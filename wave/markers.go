@@ -0,0 +1,196 @@
+package wave
+
+import (
+	"slices"
+	"wave-edit/riff"
+)
+
+type Marker struct {
+	Id       uint32 // Cue point ID
+	Position uint32 // Frame the marker is placed at
+	Length   uint32 // Frames covered by a region, 0 for a point
+	Name     string
+	Note     string
+}
+
+type Markers []Marker
+
+func (markers *Markers) nextId() uint32 {
+	var id uint32 = 1
+	for _, marker := range *markers {
+		id = max(id, marker.Id+1)
+	}
+
+	return id
+}
+
+// add a point marker, returns its ID
+func (markers *Markers) AddPoint(position uint32, name string) uint32 {
+	return markers.AddRegion(position, position, name)
+}
+
+// add a marker covering the frames [start, end), returns its ID
+func (markers *Markers) AddRegion(start, end uint32, name string) uint32 {
+	id := markers.nextId()
+	*markers = append(*markers, Marker{
+		Id:       id,
+		Position: start,
+		Length:   end - min(start, end),
+		Name:     name,
+	})
+
+	return id
+}
+
+func (markers *Markers) Remove(id uint32) {
+	*markers = slices.DeleteFunc(*markers, func(marker Marker) bool {
+		return marker.Id == id
+	})
+}
+
+func (markers Markers) Find(id uint32) *Marker {
+	for n := range markers {
+		if markers[n].Id == id {
+			return &markers[n]
+		}
+	}
+
+	return nil
+}
+
+func (markers Markers) Regions() Markers {
+	return slices.DeleteFunc(slices.Clone(markers), func(marker Marker) bool {
+		return !marker.IsRegion()
+	})
+}
+
+func (markers Markers) Points() Markers {
+	return slices.DeleteFunc(slices.Clone(markers), func(marker Marker) bool {
+		return marker.IsRegion()
+	})
+}
+
+func (marker *Marker) IsRegion() bool {
+	return marker.Length > 0
+}
+
+func (marker *Marker) End() uint32 {
+	return marker.Position + marker.Length
+}
+
+// move markers after the frames [start, end) were replaced by inserted frames
+// a region left with none of its frames is removed along with them
+func (markers *Markers) splice(start, end, inserted uint32) {
+	kept := (*markers)[:0]
+
+	for _, marker := range *markers {
+		markerEnd := moveEnd(marker.End(), start, end, inserted)
+		wasRegion := marker.IsRegion()
+
+		marker.Position = moveStart(marker.Position, start, end, inserted)
+		if wasRegion {
+			marker.Length = markerEnd - min(markerEnd, marker.Position)
+			if marker.Length == 0 {
+				continue
+			}
+		}
+
+		kept = append(kept, marker)
+	}
+
+	*markers = kept
+}
+
+// build markers from the cue chunk and the labels in the adtl list
+func markersFromChunks(cue CueChunk, adtl *riff.ListChunk[riff.Chunk]) Markers {
+	markers := make(Markers, len(cue))
+
+	for n, point := range cue {
+		markers[n] = Marker{
+			Id:       point.Id,
+			Position: point.SampleOffset,
+		}
+	}
+
+	if adtl == nil {
+		return markers
+	}
+
+	for _, chunk := range adtl.Chunks {
+		switch chunk := chunk.(type) {
+		case *LabelChunk:
+			marker := markers.Find(chunk.CueId)
+			if marker == nil {
+				continue
+			}
+
+			if chunk.ChunkId == labelChunkId {
+				marker.Name = chunk.Text
+			} else {
+				marker.Note = chunk.Text
+			}
+
+		case *LabeledTextChunk:
+			marker := markers.Find(chunk.CueId)
+			if marker == nil {
+				continue
+			}
+
+			marker.Length = chunk.SampleLength
+			if marker.Name == "" {
+				marker.Name = chunk.Text
+			}
+		}
+	}
+
+	return markers
+}
+
+// the cue chunk and adtl list to save markers with, nil without markers
+func (markers Markers) chunks() (CueChunk, *riff.ListChunk[riff.Chunk]) {
+	if len(markers) == 0 {
+		return nil, nil
+	}
+
+	cue := make(CueChunk, len(markers))
+	adtl := &riff.ListChunk[riff.Chunk]{ListType: adtlListType}
+
+	for n, marker := range markers {
+		cue[n] = CuePoint{
+			Id:           marker.Id,
+			Position:     marker.Position,
+			DataChunkId:  dataChunkId,
+			SampleOffset: marker.Position,
+		}
+
+		if marker.Name != "" {
+			adtl.Chunks = append(adtl.Chunks, &LabelChunk{
+				ChunkId: labelChunkId,
+				CueId:   marker.Id,
+				Text:    marker.Name,
+			})
+		}
+
+		if marker.Note != "" {
+			adtl.Chunks = append(adtl.Chunks, &LabelChunk{
+				ChunkId: noteChunkId,
+				CueId:   marker.Id,
+				Text:    marker.Note,
+			})
+		}
+
+		if marker.IsRegion() {
+			adtl.Chunks = append(adtl.Chunks, &LabeledTextChunk{
+				CueId:        marker.Id,
+				SampleLength: marker.Length,
+				Purpose:      regionPurpose,
+			})
+		}
+	}
+
+	if len(adtl.Chunks) == 0 {
+		return cue, nil
+	}
+
+	return cue, adtl
+}
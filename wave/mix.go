@@ -0,0 +1,133 @@
+package wave
+
+type MixInput struct {
+	Wave   *WaveFile
+	Gain   float64 // Linear gain applied to the input
+	Offset uint32  // Frame of the mix where the input starts
+}
+
+// join files end to end, overlapping each join by a linear crossfade of crossfade frames
+// inputs are converted to target first, or to the first file when target is nil
+func Concatenate(target *FmtChunk, crossfade uint32, pieces ...*WaveFile) (*WaveFile, error) {
+	return ConcatenateWithFade(target, crossfade, FADE_LINEAR, pieces...)
+}
+
+// join files end to end, overlapping each join by a crossfade of crossfade frames shaped by curve
+// a join is shortened to fit the pieces either side, pieces in the middle give at most half to each join
+func ConcatenateWithFade(target *FmtChunk, crossfade uint32, curve FadeCurve, pieces ...*WaveFile) (*WaveFile, error) {
+	if len(pieces) == 0 {
+		return nil, ErrNoTracks
+	}
+
+	if target == nil {
+		target = pieces[0].Fmt
+	}
+
+	// the converted lengths are needed to place each piece
+	converted := make([]*WaveFile, len(pieces))
+	for n, piece := range pieces {
+		wave, err := piece.convertIfNeeded(target)
+		if err != nil {
+			return nil, err
+		}
+
+		converted[n] = wave
+	}
+
+	// what each piece can give to one join without its fades overlapping
+	available := func(n int) uint32 {
+		if n == 0 || n == len(converted)-1 {
+			return converted[n].Frames()
+		}
+		return converted[n].Frames() / 2
+	}
+
+	inputs := make([]MixInput, len(converted))
+	joins := make([]uint32, len(converted)-1)
+	var offset uint32
+
+	for n, wave := range converted {
+		inputs[n] = MixInput{Wave: wave, Gain: 1, Offset: offset}
+
+		if n < len(joins) {
+			joins[n] = min(crossfade, available(n), available(n+1))
+			offset += wave.Frames() - joins[n]
+		}
+	}
+
+	return mixInputs(target, inputs, joins, curve)
+}
+
+// sum inputs, each scaled by its gain and starting at its offset
+// inputs are converted to target first, or to the first file when target is nil
+func Mix(target *FmtChunk, inputs ...MixInput) (*WaveFile, error) {
+	return mixInputs(target, inputs, nil, FADE_LINEAR)
+}
+
+// joins are the frames faded between each input and the next, none for a plain mix
+func mixInputs(target *FmtChunk, inputs []MixInput, joins []uint32, curve FadeCurve) (*WaveFile, error) {
+	if len(inputs) == 0 {
+		return nil, ErrNoTracks
+	} else if target == nil {
+		target = inputs[0].Wave.Fmt
+	}
+
+	converted := make([]*WaveFile, len(inputs))
+	var frames uint32
+
+	for n, input := range inputs {
+		wave, err := input.Wave.convertIfNeeded(target)
+		if err != nil {
+			return nil, err
+		}
+
+		converted[n] = wave
+		frames = max(frames, input.Offset+wave.Frames())
+	}
+
+	mixed := CreateWave(target.Format, target.Channels, target.SamplesPerSec)
+	mixed.Fmt.ChannelMask = target.ChannelMask
+	mixed.resize(frames)
+
+	sum := mixed.NewPlanarBuffer(frames)
+	for n, wave := range converted {
+		buffer := wave.NewPlanarBuffer(wave.Frames())
+
+		_, err := wave.ReadFrames(0, buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		var fadeIn, fadeOut uint32
+		if n > 0 && n <= len(joins) {
+			fadeIn = joins[n-1]
+		}
+		if n < len(joins) {
+			fadeOut = joins[n]
+		}
+
+		for channel, samples := range buffer {
+			for i, sample := range samples {
+				gain := inputs[n].Gain * joinGain(uint32(i), uint32(len(samples)), fadeIn, fadeOut, curve)
+				sum[channel][inputs[n].Offset+uint32(i)] += sample * gain
+			}
+		}
+	}
+
+	return mixed, mixed.WriteFrames(0, sum)
+}
+
+// fade over the first fadeIn and last fadeOut frames
+func joinGain(location, length, fadeIn, fadeOut uint32, curve FadeCurve) float64 {
+	gain := 1.0
+
+	if location < fadeIn {
+		gain *= curve.frameGain(location, fadeIn)
+	}
+
+	if fadeOut > 0 && location+fadeOut >= length {
+		gain *= curve.frameGain(length-1-location, fadeOut)
+	}
+
+	return gain
+}
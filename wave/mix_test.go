@@ -0,0 +1,122 @@
+package wave
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMix(t *testing.T) {
+	mixed, err := Mix(nil,
+		MixInput{Wave: constantWave(4, 0.5), Gain: 1},
+		MixInput{Wave: constantWave(2, 0.25), Gain: 2, Offset: 3},
+		MixInput{Wave: constantWave(1, 0.1), Gain: -1, Offset: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []float64{0.5, 0.4, 0.5, 1, 0.5}
+	got := monoFrames(t, mixed)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	for n := range got {
+		if math.Abs(got[n]-want[n]) > 1e-12 {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+
+	_, err = Mix(nil)
+	if err != ErrNoTracks {
+		t.Errorf("got error %v, want %v", err, ErrNoTracks)
+	}
+}
+
+func TestConcatenateConverts(t *testing.T) {
+	stereo := rampWave(PCM_16, 2, 100)
+	mono := constantWave(50, 0.5)
+	mono.Fmt.SamplesPerSec = 22050
+
+	target := &FmtChunk{Format: PCM_24, Channels: 2, SamplesPerSec: 44100}
+	joined, err := Concatenate(target, 10, stereo, mono)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the mono file doubles in length at the higher rate
+	if *joined.Fmt != *target {
+		t.Errorf("got format %+v, want %+v", joined.Fmt, target)
+	} else if joined.Frames() != 100+100-10 {
+		t.Errorf("got %d frames, want %d", joined.Frames(), 190)
+	}
+
+	// the first file starts untouched, the second ends at its level in both channels
+	buffer := joined.NewPlanarBuffer(joined.Frames())
+	joined.ReadFrames(0, buffer)
+
+	original := stereo.NewPlanarBuffer(10)
+	stereo.ReadFrames(0, original)
+
+	for channel := range buffer {
+		if math.Abs(buffer[channel][0]-original[channel][0]) > 1e-4 {
+			t.Errorf("channel %d starts at %f, want %f", channel, buffer[channel][0], original[channel][0])
+		}
+
+		if middle := buffer[channel][150]; math.Abs(middle-0.5) > 1e-3 {
+			t.Errorf("channel %d: second file at %f, want 0.5", channel, middle)
+		}
+	}
+}
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  uint32
+		to    uint32
+		frame uint32
+	}{
+		{"up", 44100, 48000, 48000},
+		{"down", 48000, 44100, 44100},
+		{"double", 22050, 44100, 44100},
+		{"same", 44100, 44100, 44100},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// a second of a 1kHz sine
+			wave := CreateWave(PCM_FLOAT64, 1, test.from)
+			wave.resize(test.from)
+			buffer := wave.NewPlanarBuffer(test.from)
+			for n := range buffer[0] {
+				buffer[0][n] = 0.5 * math.Sin(2*math.Pi*1000*float64(n)/float64(test.from))
+			}
+			wave.WriteFrames(0, buffer)
+
+			resampled, err := wave.Resample(test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resampled.Fmt.SamplesPerSec != test.to || resampled.Frames() != test.frame {
+				t.Fatalf("got %d frames at %dHz, want %d at %dHz",
+					resampled.Frames(), resampled.Fmt.SamplesPerSec, test.frame, test.to)
+			}
+
+			// away from the edges the sine is still a 1kHz sine of the same level
+			got := monoFrames(t, resampled)
+			for n := test.to / 10; n < test.to*9/10; n++ {
+				want := 0.5 * math.Sin(2*math.Pi*1000*float64(n)/float64(test.to))
+				if math.Abs(got[n]-want) > 1e-3 {
+					t.Fatalf("frame %d: got %f, want %f", n, got[n], want)
+				}
+			}
+		})
+	}
+
+	_, err := constantWave(10, 0).Resample(0)
+	if err != ErrInvalidSampleRate {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSampleRate)
+	}
+}
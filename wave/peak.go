@@ -0,0 +1,122 @@
+package wave
+
+import (
+	"io"
+	"math"
+	"time"
+	"wave-edit/riff"
+)
+
+type PeakChunk struct {
+	Version   uint32 // Always 1
+	Timestamp uint32 // Seconds since 1970 the peaks were found
+	Peaks     []ChannelPeak
+}
+
+type ChannelPeak struct {
+	Value    float32 // Largest absolute sample
+	Position uint32  // Frame of the largest sample
+}
+
+const peakChunkId = "PEAK"
+
+// frames decoded at once while scanning for peaks
+const peakScanFrames = 1 << 16
+
+func peakDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*PeakChunk, error) {
+	if id != peakChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 8 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	version, err := riff.DeserializeDword(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp, err := riff.DeserializeDword(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	peaks := make([]ChannelPeak, (size-8)/8)
+	for n := range peaks {
+		peaks[n], err = riff.DeserializeStruct[ChannelPeak](reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if extra := (size - 8) % 8; extra > 0 {
+		_, err = riff.DeserializeBytes(reader, extra)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &PeakChunk{
+		Version:   version,
+		Timestamp: timestamp,
+		Peaks:     peaks,
+	}, nil
+}
+
+func (chunk *PeakChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, peakChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeDword(writer, chunk.Version)
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeDword(writer, chunk.Timestamp)
+	if err != nil {
+		return err
+	}
+
+	for _, peak := range chunk.Peaks {
+		err = riff.SerializeStruct(writer, peak)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (chunk *PeakChunk) Size() uint32 {
+	return 8 + 8 + 8*uint32(len(chunk.Peaks))
+}
+
+// find the largest sample of each channel, setting the PEAK chunk
+func (wave *WaveFile) ComputePeaks() (*PeakChunk, error) {
+	peaks := make([]ChannelPeak, wave.Fmt.Channels)
+	buffer := wave.NewPlanarBuffer(min(peakScanFrames, wave.Frames()))
+
+	for start := uint32(0); start < wave.Frames(); start += peakScanFrames {
+		frames, err := wave.ReadFrames(start, buffer)
+		if err != nil {
+			return nil, err
+		}
+
+		for channel, samples := range buffer {
+			for n, sample := range samples[:frames] {
+				if value := float32(math.Abs(sample)); value > peaks[channel].Value {
+					peaks[channel] = ChannelPeak{Value: value, Position: start + uint32(n)}
+				}
+			}
+		}
+	}
+
+	wave.Peak = &PeakChunk{
+		Version:   1,
+		Timestamp: uint32(time.Now().Unix()),
+		Peaks:     peaks,
+	}
+
+	return wave.Peak, nil
+}
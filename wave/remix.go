@@ -0,0 +1,256 @@
+package wave
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+)
+
+// gain of each input channel, for each output channel
+type RemixMatrix [][]float64
+
+type speakerGain struct {
+	speaker uint32
+	gain    float64
+}
+
+var ErrInvalidMatrix = errors.New("remix matrix does not match channel count")
+var ErrUnknownLayout = errors.New("no speaker layout for channel count")
+
+const minusThreeDb = math.Sqrt2 / 2
+
+// where a speaker goes when the output layout lacks it, first usable option wins
+// coefficients follow the ITU-R BS.775 downmix
+var speakerFolds = map[uint32][][]speakerGain{
+	SPEAKER_FRONT_CENTER: {
+		{{SPEAKER_FRONT_LEFT, minusThreeDb}, {SPEAKER_FRONT_RIGHT, minusThreeDb}},
+	},
+	SPEAKER_FRONT_LEFT: {
+		{{SPEAKER_FRONT_CENTER, minusThreeDb}},
+	},
+	SPEAKER_FRONT_RIGHT: {
+		{{SPEAKER_FRONT_CENTER, minusThreeDb}},
+	},
+	SPEAKER_BACK_LEFT: {
+		{{SPEAKER_SIDE_LEFT, 1}},
+		{{SPEAKER_FRONT_LEFT, minusThreeDb}},
+		{{SPEAKER_FRONT_CENTER, 0.5}},
+	},
+	SPEAKER_BACK_RIGHT: {
+		{{SPEAKER_SIDE_RIGHT, 1}},
+		{{SPEAKER_FRONT_RIGHT, minusThreeDb}},
+		{{SPEAKER_FRONT_CENTER, 0.5}},
+	},
+	SPEAKER_SIDE_LEFT: {
+		{{SPEAKER_BACK_LEFT, 1}},
+		{{SPEAKER_FRONT_LEFT, minusThreeDb}},
+		{{SPEAKER_FRONT_CENTER, 0.5}},
+	},
+	SPEAKER_SIDE_RIGHT: {
+		{{SPEAKER_BACK_RIGHT, 1}},
+		{{SPEAKER_FRONT_RIGHT, minusThreeDb}},
+		{{SPEAKER_FRONT_CENTER, 0.5}},
+	},
+	SPEAKER_BACK_CENTER: {
+		{{SPEAKER_BACK_LEFT, minusThreeDb}, {SPEAKER_BACK_RIGHT, minusThreeDb}},
+		{{SPEAKER_SIDE_LEFT, minusThreeDb}, {SPEAKER_SIDE_RIGHT, minusThreeDb}},
+		{{SPEAKER_FRONT_LEFT, 0.5}, {SPEAKER_FRONT_RIGHT, 0.5}},
+		{{SPEAKER_FRONT_CENTER, 0.5}},
+	},
+	SPEAKER_FRONT_LEFT_OF_CENTER: {
+		{{SPEAKER_FRONT_LEFT, 1}},
+		{{SPEAKER_FRONT_CENTER, minusThreeDb}},
+	},
+	SPEAKER_FRONT_RIGHT_OF_CENTER: {
+		{{SPEAKER_FRONT_RIGHT, 1}},
+		{{SPEAKER_FRONT_CENTER, minusThreeDb}},
+	},
+}
+
+func IdentityMatrix(channels uint16) RemixMatrix {
+	matrix := make(RemixMatrix, channels)
+
+	for out := range matrix {
+		matrix[out] = make([]float64, channels)
+		matrix[out][out] = 1
+	}
+
+	return matrix
+}
+
+// each output channel copies the input channel at the same position in routes
+func RouteMatrix(inputChannels uint16, routes []uint16) (RemixMatrix, error) {
+	matrix := make(RemixMatrix, len(routes))
+
+	for out, in := range routes {
+		if in >= inputChannels {
+			return nil, ErrChannelDoesNotExist
+		}
+
+		matrix[out] = make([]float64, inputChannels)
+		matrix[out][in] = 1
+	}
+
+	return matrix, nil
+}
+
+func SplitMatrix(inputChannels uint16, channel uint16) (RemixMatrix, error) {
+	return RouteMatrix(inputChannels, []uint16{channel})
+}
+
+func SwapMatrix(channels uint16, a, b uint16) (RemixMatrix, error) {
+	if a >= channels || b >= channels {
+		return nil, ErrChannelDoesNotExist
+	}
+
+	matrix := IdentityMatrix(channels)
+	matrix[a][a], matrix[a][b] = 0, 1
+	matrix[b][b], matrix[b][a] = 0, 1
+
+	return matrix, nil
+}
+
+func PolarityMatrix(channels uint16, flipped ...uint16) (RemixMatrix, error) {
+	matrix := IdentityMatrix(channels)
+
+	for _, channel := range flipped {
+		if channel >= channels {
+			return nil, ErrChannelDoesNotExist
+		}
+
+		matrix[channel][channel] = -1
+	}
+
+	return matrix, nil
+}
+
+// build a matrix moving each speaker of the input layout onto the output layout
+func LayoutMatrix(input *FmtChunk, outputMask uint32) (RemixMatrix, error) {
+	inputSpeakers := input.Speakers()
+	outputSpeakers := channelSpeakers(outputMask, uint16(bits.OnesCount32(outputMask)))
+
+	if len(outputSpeakers) == 0 {
+		return nil, ErrUnknownLayout
+	}
+
+	outputIndex := map[uint32]int{}
+	for out, speaker := range outputSpeakers {
+		outputIndex[speaker] = out
+	}
+
+	matrix := make(RemixMatrix, len(outputSpeakers))
+	for out := range matrix {
+		matrix[out] = make([]float64, len(inputSpeakers))
+	}
+
+	for in, speaker := range inputSpeakers {
+		if speaker == 0 {
+			return nil, ErrUnknownLayout
+		}
+
+		if out, ok := outputIndex[speaker]; ok {
+			matrix[out][in] = 1
+			continue
+		}
+
+		// a mono source spreads to both fronts at full level
+		if speaker == SPEAKER_FRONT_CENTER && len(inputSpeakers) == 1 {
+			left, hasLeft := outputIndex[SPEAKER_FRONT_LEFT]
+			right, hasRight := outputIndex[SPEAKER_FRONT_RIGHT]
+			if hasLeft && hasRight {
+				matrix[left][in] = 1
+				matrix[right][in] = 1
+				continue
+			}
+		}
+
+		for _, fold := range speakerFolds[speaker] {
+			if !hasSpeakers(outputIndex, fold) {
+				continue
+			}
+
+			for _, target := range fold {
+				matrix[outputIndex[target.speaker]][in] = target.gain
+			}
+			break
+		}
+	}
+
+	return matrix, nil
+}
+
+func (wave *WaveFile) Remix(matrix RemixMatrix, outputMask uint32) (*WaveFile, error) {
+	if len(matrix) == 0 || len(matrix) > math.MaxUint16 {
+		return nil, ErrInvalidMatrix
+	} else if outputMask != 0 && bits.OnesCount32(outputMask) != len(matrix) {
+		return nil, ErrInvalidMatrix
+	}
+
+	for _, row := range matrix {
+		if len(row) != int(wave.Fmt.Channels) {
+			return nil, ErrInvalidMatrix
+		}
+	}
+
+	sampleCount := wave.Fact.Samples()
+	remixed := CreateWave(wave.Fmt.Format, uint16(len(matrix)), wave.Fmt.SamplesPerSec)
+	remixed.Fmt.ChannelMask = outputMask
+	remixed.resize(sampleCount)
+
+	inputs := make([][]float64, wave.Fmt.Channels)
+	for channel := range wave.Fmt.Channels {
+		samples, err := wave.GetSamples(channel, 0, sampleCount)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs[channel] = samples
+	}
+
+	output := make([]float64, sampleCount)
+	for out, row := range matrix {
+		clear(output)
+
+		for in, gain := range row {
+			if gain == 0 {
+				continue
+			}
+
+			for n, sample := range inputs[in] {
+				output[n] += sample * gain
+			}
+		}
+
+		err := remixed.SetSamples(uint16(out), 0, output)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return remixed, nil
+}
+
+// remix onto a speaker layout using the ITU downmix and upmix presets
+func (wave *WaveFile) RemixLayout(outputMask uint32) (*WaveFile, error) {
+	matrix, err := LayoutMatrix(wave.Fmt, outputMask)
+	if err != nil {
+		return nil, err
+	}
+
+	// plain mono and stereo files don't need WAVE_FORMAT_EXTENSIBLE
+	if outputMask == defaultChannelMask(uint16(len(matrix))) && len(matrix) <= 2 {
+		outputMask = 0
+	}
+
+	return wave.Remix(matrix, outputMask)
+}
+
+func hasSpeakers(outputIndex map[uint32]int, fold []speakerGain) bool {
+	for _, target := range fold {
+		if _, ok := outputIndex[target.speaker]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
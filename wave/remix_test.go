@@ -0,0 +1,117 @@
+package wave
+
+import (
+	"math"
+	"testing"
+)
+
+func TestChannelMatrices(t *testing.T) {
+	tests := []struct {
+		name   string
+		matrix func() (RemixMatrix, error)
+		want   RemixMatrix
+		err    error
+	}{
+		{
+			"swap",
+			func() (RemixMatrix, error) { return SwapMatrix(3, 0, 2) },
+			RemixMatrix{{0, 0, 1}, {0, 1, 0}, {1, 0, 0}},
+			nil,
+		},
+		{
+			"swap missing channel",
+			func() (RemixMatrix, error) { return SwapMatrix(2, 0, 2) },
+			nil,
+			ErrChannelDoesNotExist,
+		},
+		{
+			"polarity",
+			func() (RemixMatrix, error) { return PolarityMatrix(2, 1) },
+			RemixMatrix{{1, 0}, {0, -1}},
+			nil,
+		},
+		{
+			"route",
+			func() (RemixMatrix, error) { return RouteMatrix(2, []uint16{1, 0, 1}) },
+			RemixMatrix{{0, 1}, {1, 0}, {0, 1}},
+			nil,
+		},
+		{
+			"route missing channel",
+			func() (RemixMatrix, error) { return RouteMatrix(2, []uint16{0, 2}) },
+			nil,
+			ErrChannelDoesNotExist,
+		},
+		{
+			"split",
+			func() (RemixMatrix, error) { return SplitMatrix(3, 2) },
+			RemixMatrix{{0, 0, 1}},
+			nil,
+		},
+		{
+			"split missing channel",
+			func() (RemixMatrix, error) { return SplitMatrix(3, 3) },
+			nil,
+			ErrChannelDoesNotExist,
+		},
+		{
+			"polarity missing channel",
+			func() (RemixMatrix, error) { return PolarityMatrix(2, 0, 5) },
+			nil,
+			ErrChannelDoesNotExist,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matrix, err := test.matrix()
+			if err != test.err {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+
+			for out := range test.want {
+				for in := range test.want[out] {
+					if matrix[out][in] != test.want[out][in] {
+						t.Fatalf("got %v, want %v", matrix, test.want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRemixLayout(t *testing.T) {
+	wave := CreateWave(PCM_FLOAT64, 6, 48000)
+	wave.Fmt.ChannelMask = LAYOUT_5_1
+	wave.resize(6)
+
+	// one frame with a unit impulse on each speaker in turn
+	buffer := wave.NewPlanarBuffer(6)
+	for channel := range buffer {
+		buffer[channel][channel] = 1
+	}
+	wave.WriteFrames(0, buffer)
+
+	stereo, err := wave.RemixLayout(LAYOUT_STEREO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := stereo.NewPlanarBuffer(6)
+	stereo.ReadFrames(0, output)
+
+	// left, right, centre, LFE, back left, back right
+	want := [][]float64{
+		{1, 0, minusThreeDb, 0, minusThreeDb, 0},
+		{0, 1, minusThreeDb, 0, 0, minusThreeDb},
+	}
+
+	for channel := range want {
+		for n := range want[channel] {
+			if math.Abs(output[channel][n]-want[channel][n]) > 1e-12 {
+				t.Errorf("got %v, want %v", output, want)
+				return
+			}
+		}
+	}
+}
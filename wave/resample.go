@@ -0,0 +1,83 @@
+package wave
+
+import (
+	"errors"
+	"math"
+)
+
+// zero crossings of the sinc kernel either side of a sample
+const resampleZeroCrossings = 16
+
+var ErrInvalidSampleRate = errors.New("sample rate must be above zero")
+
+// a new file at another sample rate, using a windowed sinc interpolator
+func (wave *WaveFile) Resample(samplesPerSec uint32) (*WaveFile, error) {
+	if samplesPerSec == 0 || wave.Fmt.SamplesPerSec == 0 {
+		return nil, ErrInvalidSampleRate
+	}
+
+	frames := uint32((uint64(wave.Frames())*uint64(samplesPerSec) + uint64(wave.Fmt.SamplesPerSec) - 1) / uint64(wave.Fmt.SamplesPerSec))
+
+	resampled := CreateWave(wave.Fmt.Format, wave.Fmt.Channels, samplesPerSec)
+	resampled.Fmt.ChannelMask = wave.Fmt.ChannelMask
+	resampled.resize(frames)
+
+	input := wave.NewPlanarBuffer(wave.Frames())
+	output := resampled.NewPlanarBuffer(frames)
+
+	_, err := wave.ReadFrames(0, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ratio := float64(samplesPerSec) / float64(wave.Fmt.SamplesPerSec)
+	for channel := range input {
+		ResampleSamples(output[channel], input[channel], ratio)
+	}
+
+	return resampled, resampled.WriteFrames(0, output)
+}
+
+// fill output with input played at ratio output samples per input sample
+func ResampleSamples(output, input []float64, ratio float64) {
+	if ratio == 1 {
+		copy(output, input)
+		return
+	}
+
+	// lower the cutoff when downsampling so nothing folds back
+	cutoff := min(1, ratio)
+	width := math.Ceil(resampleZeroCrossings / cutoff)
+
+	for n := range output {
+		position := float64(n) / ratio
+		first := max(0, int(math.Floor(position-width))+1)
+		last := min(len(input)-1, int(math.Floor(position+width)))
+
+		var sum float64
+		for k := first; k <= last; k++ {
+			distance := position - float64(k)
+			sum += input[k] * cutoff * sinc(cutoff*distance) * blackman(distance/width)
+		}
+
+		output[n] = sum
+	}
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// Blackman window over [-1, 1]
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+
+	phase := math.Pi * (x + 1)
+	return 0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase)
+}
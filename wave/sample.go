@@ -37,7 +37,7 @@ func (wave *WaveFile) MapSamples(channel uint16, start uint32, end uint32, mappe
 }
 
 func (wave *WaveFile) GetSample(channel uint16, location uint32) (float64, error) {
-	samples, err := wave.GetSamples(channel, location, 1)
+	samples, err := wave.GetSamples(channel, location, location+1)
 	if err != nil {
 		return 0, err
 	}
@@ -54,19 +54,8 @@ func (wave *WaveFile) GetSamples(channel uint16, start, end uint32) ([]float64,
 		return nil, ErrChannelDoesNotExist
 	}
 
-	_, byteDepth := wave.Fmt.Format.Properties()
-	blockSize := uint32(wave.Fmt.BlockSize())
-	index := blockSize*start + uint32(byteDepth*channel)
-	getter := wave.Fmt.Format.SampleGetter()
-
-	length := end - start
-	samples := make([]float64, length)
-	for n := range length {
-		sampleData := wave.Data[index : index+uint32(byteDepth)]
-		index += blockSize
-
-		samples[n] = getter(sampleData)
-	}
+	samples := make([]float64, end-start)
+	wave.decodeChannel(channel, start, samples)
 
 	return samples, nil
 }
@@ -82,17 +71,24 @@ func (wave *WaveFile) SetSamples(channel uint16, location uint32, samples []floa
 		return ErrChannelDoesNotExist
 	}
 
+	wave.encodeChannel(channel, location, samples)
+
+	wave.clearPeaks()
+	return nil
+}
+
+func (wave *WaveFile) decodeChannel(channel uint16, start uint32, samples []float64) {
 	_, byteDepth := wave.Fmt.Format.Properties()
 	blockSize := uint32(wave.Fmt.BlockSize())
-	index := blockSize*location + uint32(byteDepth*channel)
-	setter := wave.Fmt.Format.SampleSetter()
+	index := blockSize*start + uint32(byteDepth*channel)
 
-	for _, sample := range samples {
-		sampleData := wave.Data[index : index+uint32(byteDepth)]
-		index += blockSize
+	wave.Fmt.Format.DecodeSamples(samples, wave.Data[index:], int(blockSize))
+}
 
-		setter(sampleData, sample)
-	}
+func (wave *WaveFile) encodeChannel(channel uint16, start uint32, samples []float64) {
+	_, byteDepth := wave.Fmt.Format.Properties()
+	blockSize := uint32(wave.Fmt.BlockSize())
+	index := blockSize*start + uint32(byteDepth*channel)
 
-	return nil
+	wave.Fmt.Format.EncodeSamples(wave.Data[index:], int(blockSize), samples)
 }
@@ -0,0 +1,171 @@
+package wave
+
+import (
+	"io"
+	"wave-edit/riff"
+)
+
+type SamplerChunk struct {
+	Manufacturer      uint32 // MIDI manufacturer code
+	Product           uint32 // Manufacturer's product code
+	SamplePeriod      uint32 // Nanoseconds per sample
+	MidiUnityNote     uint32 // MIDI note played at the recorded pitch
+	MidiPitchFraction uint32 // Fraction of a semitone above the unity note
+	SmpteFormat       uint32 // Frames per second of SmpteOffset
+	SmpteOffset       uint32 // Time of the first sample, as 0xhhmmssff
+	Loops             []SampleLoop
+	SamplerData       []byte // Sampler specific data
+}
+
+type SampleLoop struct {
+	CuePointId uint32 // Cue point marking the loop, 0 if none
+	Type       uint32 // How the loop plays
+	Start      uint32 // First frame of the loop
+	End        uint32 // Last frame of the loop, played
+	Fraction   uint32 // Fraction of a sample to loop at
+	PlayCount  uint32 // Times to play the loop, 0 forever
+}
+
+type rawSamplerChunk struct {
+	Manufacturer      uint32
+	Product           uint32
+	SamplePeriod      uint32
+	MidiUnityNote     uint32
+	MidiPitchFraction uint32
+	SmpteFormat       uint32
+	SmpteOffset       uint32
+	SampleLoops       uint32
+	SamplerDataSize   uint32
+}
+
+const samplerChunkId = "smpl"
+
+const (
+	LOOP_FORWARD uint32 = iota
+	LOOP_ALTERNATING
+	LOOP_BACKWARD
+)
+
+func samplerDeserializer(reader io.Reader, id riff.FourCC, size uint32) (*SamplerChunk, error) {
+	if id != samplerChunkId {
+		return nil, riff.ErrUnexpectedChunkId
+	} else if size < 36 {
+		return nil, riff.ErrUnexpectedEnd
+	}
+
+	raw, err := riff.DeserializeStruct[rawSamplerChunk](reader)
+	if err != nil {
+		return nil, err
+	} else if 36+uint64(raw.SampleLoops)*24+uint64(raw.SamplerDataSize) > uint64(size) {
+		return nil, riff.ErrReadTooMuch
+	}
+
+	loops := make([]SampleLoop, raw.SampleLoops)
+	for n := range loops {
+		loops[n], err = riff.DeserializeStruct[SampleLoop](reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	samplerData, err := riff.DeserializeBytes(reader, size-36-raw.SampleLoops*24)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SamplerChunk{
+		Manufacturer:      raw.Manufacturer,
+		Product:           raw.Product,
+		SamplePeriod:      raw.SamplePeriod,
+		MidiUnityNote:     raw.MidiUnityNote,
+		MidiPitchFraction: raw.MidiPitchFraction,
+		SmpteFormat:       raw.SmpteFormat,
+		SmpteOffset:       raw.SmpteOffset,
+		Loops:             loops,
+		SamplerData:       samplerData[:raw.SamplerDataSize],
+	}, nil
+}
+
+func (chunk *SamplerChunk) Serialize(writer io.Writer) error {
+	err := riff.SerializeChunkHeader(writer, samplerChunkId, chunk.Size())
+	if err != nil {
+		return err
+	}
+
+	err = riff.SerializeStruct(writer, rawSamplerChunk{
+		Manufacturer:      chunk.Manufacturer,
+		Product:           chunk.Product,
+		SamplePeriod:      chunk.SamplePeriod,
+		MidiUnityNote:     chunk.MidiUnityNote,
+		MidiPitchFraction: chunk.MidiPitchFraction,
+		SmpteFormat:       chunk.SmpteFormat,
+		SmpteOffset:       chunk.SmpteOffset,
+		SampleLoops:       uint32(len(chunk.Loops)),
+		SamplerDataSize:   uint32(len(chunk.SamplerData)),
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, loop := range chunk.Loops {
+		err = riff.SerializeStruct(writer, loop)
+		if err != nil {
+			return err
+		}
+	}
+
+	return riff.SerializeBytes(writer, chunk.SamplerData)
+}
+
+func (chunk *SamplerChunk) Size() uint32 {
+	return 8 + 36 + 24*uint32(len(chunk.Loops)) + uint32(len(chunk.SamplerData))
+}
+
+// a sampler chunk for the file, tuned to middle C with no loops
+func (wave *WaveFile) NewSampler() *SamplerChunk {
+	var samplePeriod uint32
+	if wave.Fmt.SamplesPerSec > 0 {
+		samplePeriod = 1_000_000_000 / wave.Fmt.SamplesPerSec
+	}
+
+	return &SamplerChunk{
+		SamplePeriod:  samplePeriod,
+		MidiUnityNote: 60,
+		Loops:         []SampleLoop{},
+	}
+}
+
+// a forward loop over a region marker, linked to its cue point
+func LoopFromRegion(region Marker) SampleLoop {
+	return SampleLoop{
+		CuePointId: region.Id,
+		Type:       LOOP_FORWARD,
+		Start:      region.Position,
+		End:        region.End() - min(region.End(), 1),
+	}
+}
+
+// replace the sampler loops with one forward loop for each region marker
+func (wave *WaveFile) SetLoopsFromRegions() {
+	if wave.Sampler == nil {
+		wave.Sampler = wave.NewSampler()
+	}
+
+	regions := wave.Markers.Regions()
+	wave.Sampler.Loops = make([]SampleLoop, len(regions))
+
+	for n, region := range regions {
+		wave.Sampler.Loops[n] = LoopFromRegion(region)
+	}
+}
+
+// move loops after the frames [start, end) were replaced by inserted frames
+func (chunk *SamplerChunk) splice(start, end, inserted uint32) {
+	for n := range chunk.Loops {
+		loop := &chunk.Loops[n]
+		loopEnd := moveEnd(loop.End+1, start, end, inserted)
+
+		loop.Start = moveStart(loop.Start, start, end, inserted)
+		loop.End = max(loop.Start, loopEnd-min(loopEnd, 1))
+	}
+}
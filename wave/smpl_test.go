@@ -0,0 +1,70 @@
+package wave
+
+import (
+	"reflect"
+	"testing"
+)
+
+func rawSampler(loops []SampleLoop, samplerData []byte, extra int) []byte {
+	data := rawStruct(rawSamplerChunk{
+		SamplePeriod:    22675,
+		MidiUnityNote:   60,
+		SampleLoops:     uint32(len(loops)),
+		SamplerDataSize: uint32(len(samplerData)),
+	})
+
+	for _, loop := range loops {
+		data = append(data, rawStruct(loop)...)
+	}
+
+	data = append(data, samplerData...)
+	return rawChunk(samplerChunkId, append(data, make([]byte, extra)...))
+}
+
+func TestSamplerChunk(t *testing.T) {
+	loop := SampleLoop{CuePointId: 1, Type: LOOP_FORWARD, Start: 1, End: 3}
+
+	tests := []struct {
+		name        string
+		loops       []SampleLoop
+		samplerData []byte
+		extra       int
+	}{
+		{"no loops", []SampleLoop{}, []byte{}, 0},
+		{"loops", []SampleLoop{loop, loop}, []byte{}, 0},
+		{"sampler data", []SampleLoop{loop}, []byte{1, 2, 3, 4}, 0},
+		{"odd sampler data", []SampleLoop{loop}, []byte{1, 2, 3}, 0},
+		{"trailing bytes", []SampleLoop{loop}, []byte{1, 2}, 6},
+		{"odd trailing bytes", []SampleLoop{}, []byte{}, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawStereoWave(rawSampler(test.loops, test.samplerData, test.extra)))
+
+			for _, sampler := range []*SamplerChunk{wave.Sampler, roundTrip(t, wave).Sampler} {
+				if sampler == nil {
+					t.Fatal("no sampler chunk")
+				} else if sampler.MidiUnityNote != 60 || sampler.SamplePeriod != 22675 {
+					t.Errorf("got %+v", sampler)
+				} else if !reflect.DeepEqual(sampler.Loops, test.loops) {
+					t.Errorf("got loops %+v, want %+v", sampler.Loops, test.loops)
+				} else if !reflect.DeepEqual(sampler.SamplerData, test.samplerData) {
+					t.Errorf("got sampler data %v, want %v", sampler.SamplerData, test.samplerData)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplerTooShort(t *testing.T) {
+	data := rawSampler([]SampleLoop{{}}, nil, 0)
+
+	// claim a second loop that isn't there
+	data[8+28] = 2
+
+	_, err := loadWaveError(rawStereoWave(data))
+	if err == nil {
+		t.Error("loaded a sampler chunk with a missing loop")
+	}
+}
@@ -0,0 +1,76 @@
+package wave
+
+import "math/bits"
+
+const (
+	SPEAKER_FRONT_LEFT uint32 = 1 << iota
+	SPEAKER_FRONT_RIGHT
+	SPEAKER_FRONT_CENTER
+	SPEAKER_LOW_FREQUENCY
+	SPEAKER_BACK_LEFT
+	SPEAKER_BACK_RIGHT
+	SPEAKER_FRONT_LEFT_OF_CENTER
+	SPEAKER_FRONT_RIGHT_OF_CENTER
+	SPEAKER_BACK_CENTER
+	SPEAKER_SIDE_LEFT
+	SPEAKER_SIDE_RIGHT
+	SPEAKER_TOP_CENTER
+	SPEAKER_TOP_FRONT_LEFT
+	SPEAKER_TOP_FRONT_CENTER
+	SPEAKER_TOP_FRONT_RIGHT
+	SPEAKER_TOP_BACK_LEFT
+	SPEAKER_TOP_BACK_CENTER
+	SPEAKER_TOP_BACK_RIGHT
+)
+
+const (
+	LAYOUT_MONO     = SPEAKER_FRONT_CENTER
+	LAYOUT_STEREO   = SPEAKER_FRONT_LEFT | SPEAKER_FRONT_RIGHT
+	LAYOUT_QUAD     = LAYOUT_STEREO | SPEAKER_BACK_LEFT | SPEAKER_BACK_RIGHT
+	LAYOUT_5_1      = LAYOUT_STEREO | SPEAKER_FRONT_CENTER | SPEAKER_LOW_FREQUENCY | SPEAKER_BACK_LEFT | SPEAKER_BACK_RIGHT
+	LAYOUT_5_1_SIDE = LAYOUT_STEREO | SPEAKER_FRONT_CENTER | SPEAKER_LOW_FREQUENCY | SPEAKER_SIDE_LEFT | SPEAKER_SIDE_RIGHT
+	LAYOUT_7_1      = LAYOUT_5_1 | SPEAKER_SIDE_LEFT | SPEAKER_SIDE_RIGHT
+)
+
+// the layout a player would assume for a file without a channel mask
+func defaultChannelMask(channels uint16) uint32 {
+	switch channels {
+	case 1:
+		return LAYOUT_MONO
+	case 2:
+		return LAYOUT_STEREO
+	case 4:
+		return LAYOUT_QUAD
+	case 6:
+		return LAYOUT_5_1
+	case 8:
+		return LAYOUT_7_1
+	default:
+		return 0
+	}
+}
+
+// speaker of each channel in order, channels past the mask are left as 0
+func channelSpeakers(mask uint32, channels uint16) []uint32 {
+	speakers := make([]uint32, channels)
+
+	for n := range speakers {
+		if mask == 0 {
+			break
+		}
+
+		speakers[n] = 1 << bits.TrailingZeros32(mask)
+		mask &= mask - 1
+	}
+
+	return speakers
+}
+
+func (chunk *FmtChunk) Speakers() []uint32 {
+	mask := chunk.ChannelMask
+	if mask == 0 {
+		mask = defaultChannelMask(chunk.Channels)
+	}
+
+	return channelSpeakers(mask, chunk.Channels)
+}
@@ -0,0 +1,357 @@
+package wave
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+// playback speed at a point of the source, in seconds from the start of the range, 1 is normal
+type SpeedCurve func(seconds float64) float64
+
+type SpeedPoint struct {
+	Time  float64 // Seconds from the start of the range
+	Speed float64
+}
+
+// slower would make a file a hundred times longer
+const MIN_SPEED = 0.01
+
+// stretches past these make a hundredth or a hundred times the length
+const MIN_STRETCH = 0.01
+const MAX_STRETCH = 100
+
+// zero crossings of the varispeed kernel, fewer than resampling as the cutoff moves every sample
+const varispeedZeroCrossings = 8
+
+// WSOLA frames and how far each may shift to line up with the last, in seconds
+const stretchFrameSeconds = 0.04
+const stretchToleranceSeconds = 0.01
+
+// every how many samples the coarse search for a matching frame looks
+const stretchCoarseStep = 4
+
+var ErrInvalidSpeed = errors.New("speed must be at least 0.01, and fit in the file")
+var ErrInvalidStretch = errors.New("stretch must be between 0.01 and 100, and fit in the file")
+var ErrNoTempo = errors.New("file has no tempo")
+
+// a speed curve moving in straight lines between points sorted by time, held before the first and after the last
+func SpeedBreakpoints(points ...SpeedPoint) SpeedCurve {
+	return func(seconds float64) float64 {
+		if len(points) == 0 {
+			return 1
+		}
+
+		next, _ := slices.BinarySearchFunc(points, seconds, func(point SpeedPoint, seconds float64) int {
+			if point.Time < seconds {
+				return -1
+			} else if point.Time > seconds {
+				return 1
+			}
+			return 0
+		})
+
+		if next == 0 {
+			return points[0].Speed
+		} else if next == len(points) {
+			return points[len(points)-1].Speed
+		}
+
+		before, after := points[next-1], points[next]
+		if after.Time == before.Time {
+			return after.Speed
+		}
+
+		fade := (seconds - before.Time) / (after.Time - before.Time)
+		return before.Speed*(1-fade) + after.Speed*fade
+	}
+}
+
+// play the frames [start, end) backwards, mirroring the markers inside them
+func (wave *WaveFile) Reverse(start, end uint32) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	buffer := wave.NewPlanarBuffer(end - start)
+	_, err := wave.ReadFrames(start, buffer)
+	if err != nil {
+		return err
+	}
+
+	for _, samples := range buffer {
+		slices.Reverse(samples)
+	}
+
+	for n := range wave.Markers {
+		marker := &wave.Markers[n]
+		if marker.IsRegion() && marker.Position >= start && marker.End() <= end {
+			marker.Position = start + end - marker.End()
+		} else if !marker.IsRegion() && marker.Position >= start && marker.Position < end {
+			marker.Position = start + end - 1 - marker.Position
+		}
+	}
+
+	return wave.WriteFrames(start, buffer)
+}
+
+// replace the frames [start, end) by playing them at a varying speed, changing pitch and length together
+func (wave *WaveFile) Varispeed(start, end uint32, speed SpeedCurve) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	}
+
+	input := wave.NewPlanarBuffer(end - start)
+	_, err := wave.ReadFrames(start, input)
+	if err != nil {
+		return err
+	}
+
+	output := make([][]float64, len(input))
+	length := float64(end - start)
+	rate := float64(wave.Fmt.SamplesPerSec)
+
+	// the output frame each input frame ends up at, for moving markers
+	positions := make([]uint32, end-start+1)
+	reached := 0
+
+	// the output and the rest of the file have to fit the data chunk
+	limit := math.MaxUint32/uint32(wave.Fmt.BlockSize()) - (wave.Frames() - (end - start))
+
+	var frames uint32
+	for phase := 0.0; phase < length; frames++ {
+		if frames >= limit {
+			return ErrInvalidSpeed
+		}
+
+		for ; reached <= int(phase); reached++ {
+			positions[reached] = frames
+		}
+
+		step := speed(phase / rate)
+		if !(step >= MIN_SPEED) {
+			return ErrInvalidSpeed
+		}
+
+		// faster playback needs a lower cutoff so nothing folds back
+		cutoff := min(1, 1/step)
+		for channel, samples := range input {
+			output[channel] = append(output[channel], interpolate(samples, phase, cutoff))
+		}
+
+		phase += step
+	}
+
+	for ; reached < len(positions); reached++ {
+		positions[reached] = frames
+	}
+
+	return wave.replaceFrames(start, end, output, func(offset uint32) uint32 {
+		return positions[offset]
+	})
+}
+
+// band limited value of samples between frames, with the cutoff as a fraction of the sample rate
+func interpolate(samples []float64, position, cutoff float64) float64 {
+	width := math.Ceil(varispeedZeroCrossings / cutoff)
+	first := max(0, int(math.Floor(position-width))+1)
+	last := min(len(samples)-1, int(math.Floor(position+width)))
+
+	var sum float64
+	for k := first; k <= last; k++ {
+		distance := position - float64(k)
+		sum += samples[k] * cutoff * sinc(cutoff*distance) * blackman(distance/width)
+	}
+
+	return sum
+}
+
+// change the length of the frames [start, end) by stretch without changing pitch, using WSOLA
+// a stretch of 2 plays twice as long
+func (wave *WaveFile) TimeStretch(start, end uint32, stretch float64) error {
+	if end > wave.Frames() {
+		return ErrSampleOutOfRange
+	} else if end < start {
+		return ErrInvalidSampleRange
+	} else if !(stretch >= MIN_STRETCH && stretch <= MAX_STRETCH) {
+		return ErrInvalidStretch
+	}
+
+	// the stretched frames and the rest of the file have to fit the data chunk
+	stretched := math.Round(float64(end-start) * stretch)
+	if (stretched+float64(wave.Frames()-(end-start)))*float64(wave.Fmt.BlockSize()) > math.MaxUint32 {
+		return ErrInvalidStretch
+	}
+
+	input := wave.NewPlanarBuffer(end - start)
+	_, err := wave.ReadFrames(start, input)
+	if err != nil {
+		return err
+	}
+
+	rate := float64(wave.Fmt.SamplesPerSec)
+	frameLength := max(4, 2*int(math.Round(stretchFrameSeconds*rate/2)))
+	synthesisHop := frameLength / 2
+	analysisHop := float64(synthesisHop) / stretch
+	tolerance := int(math.Round(stretchToleranceSeconds * rate))
+
+	length := int(end - start)
+	frames := int(stretched)
+
+	// frames are lined up on the mix of every channel
+	mono := make([]float64, length)
+	for _, samples := range input {
+		for n, sample := range samples {
+			mono[n] += sample
+		}
+	}
+
+	window := make([]float64, frameLength)
+	for n := range window {
+		window[n] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(n)/float64(frameLength))
+	}
+
+	output := wave.NewPlanarBuffer(uint32(frames))
+	weights := make([]float64, frames)
+
+	// the first frame starts half a frame early so every output frame is covered twice
+	previous := -synthesisHop
+	for k := 0; k*synthesisHop-synthesisHop < frames; k++ {
+		outStart := k*synthesisHop - synthesisHop
+		position := int(math.Round(float64(outStart) * analysisHop / float64(synthesisHop)))
+
+		if k > 0 {
+			position = bestOverlap(mono, previous+synthesisHop, position, tolerance, synthesisHop)
+		}
+		previous = position
+
+		for n, weight := range window {
+			out := outStart + n
+			if out < 0 || out >= frames {
+				continue
+			}
+
+			weights[out] += weight
+			if in := position + n; in >= 0 && in < length {
+				for channel, samples := range input {
+					output[channel][out] += weight * samples[in]
+				}
+			}
+		}
+	}
+
+	for channel := range output {
+		for n, weight := range weights {
+			if weight > 1e-9 {
+				output[channel][n] /= weight
+			}
+		}
+	}
+
+	return wave.replaceFrames(start, end, output, func(offset uint32) uint32 {
+		return uint32(math.Round(float64(offset) * stretch))
+	})
+}
+
+// the frame near nominal whose start best matches the natural continuation of the last frame
+func bestOverlap(samples []float64, natural, nominal, tolerance, overlap int) int {
+	correlation := func(candidate, step int) float64 {
+		var sum float64
+		for n := 0; n < overlap; n += step {
+			a, b := natural+n, candidate+n
+			if a >= 0 && a < len(samples) && b >= 0 && b < len(samples) {
+				sum += samples[a] * samples[b]
+			}
+		}
+		return sum
+	}
+
+	// a coarse look over the whole tolerance, then a fine one around the best
+	best, bestScore := nominal, math.Inf(-1)
+	for offset := -tolerance; offset <= tolerance; offset += stretchCoarseStep {
+		if score := correlation(nominal+offset, stretchCoarseStep); score > bestScore {
+			best, bestScore = nominal+offset, score
+		}
+	}
+
+	coarse := best
+	bestScore = math.Inf(-1)
+	for offset := -stretchCoarseStep + 1; offset < stretchCoarseStep; offset++ {
+		candidate := coarse + offset
+		if candidate < nominal-tolerance || candidate > nominal+tolerance {
+			continue
+		}
+
+		if score := correlation(candidate, 1); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+
+	return best
+}
+
+// time stretch the whole file from the tempo in its acid chunk to bpm, keeping its pitch
+func (wave *WaveFile) FitTempo(bpm float64) error {
+	if wave.Acid == nil || !wave.Acid.HasTempo() {
+		return ErrNoTempo
+	} else if !(bpm > 0) {
+		return ErrInvalidStretch
+	}
+
+	err := wave.TimeStretch(0, wave.Frames(), float64(wave.Acid.Tempo)/bpm)
+	if err != nil {
+		return err
+	}
+
+	wave.Acid.Tempo = float32(bpm)
+	return nil
+}
+
+// replace the frames [start, end) with planar frames, taking markers inside through position
+// position maps an offset into the old range, up to its length, to an offset into the new one
+func (wave *WaveFile) replaceFrames(start, end uint32, frames [][]float64, position func(offset uint32) uint32) error {
+	clip := CreateWave(wave.Fmt.Format, wave.Fmt.Channels, wave.Fmt.SamplesPerSec)
+	clip.resize(planarLength(frames))
+
+	err := clip.WriteFrames(0, frames)
+	if err != nil {
+		return err
+	}
+
+	original := slices.Clone(wave.Markers)
+	inserted := planarLength(frames)
+
+	err = wave.spliceFrames(start, end, clip.Data)
+	if err != nil {
+		return err
+	}
+
+	// markers outside the range move as the splice moves them, those inside move with the audio
+	// working from the originals keeps regions the splice would remove for having no frames left
+	wave.Markers = make(Markers, len(original))
+	for n, before := range original {
+		marker := before
+		marker.Position = moveStart(before.Position, start, end, inserted)
+		markerEnd := moveEnd(before.End(), start, end, inserted)
+
+		if before.Position >= start && before.Position < end {
+			marker.Position = start + position(before.Position-start)
+		}
+
+		if before.IsRegion() {
+			if before.End() > start && before.End() <= end {
+				markerEnd = start + position(before.End()-start)
+			}
+
+			marker.Length = markerEnd - min(markerEnd, marker.Position)
+		}
+
+		wave.Markers[n] = marker
+	}
+
+	return nil
+}
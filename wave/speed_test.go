@@ -0,0 +1,188 @@
+package wave
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestTimeStretch(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   uint32
+		end     uint32
+		stretch float64
+		frames  uint32 // length of the range once stretched
+		err     error
+	}{
+		{"twice as long", 0, 4800, 2, 9600, nil},
+		{"half as long", 0, 4800, 0.5, 2400, nil},
+		{"part of the file", 1200, 3600, 1.5, 3600, nil},
+		{"empty range", 100, 100, 2, 0, nil},
+		{"smallest", 0, 4800, MIN_STRETCH, 48, nil},
+		{"largest", 0, 48, MAX_STRETCH, 4800, nil},
+		{"zero", 0, 4800, 0, 0, ErrInvalidStretch},
+		{"too small", 0, 4800, MIN_STRETCH / 2, 0, ErrInvalidStretch},
+		{"too large", 0, 4800, MAX_STRETCH * 2, 0, ErrInvalidStretch},
+		{"not a number", 0, 4800, math.NaN(), 0, ErrInvalidStretch},
+		{"past the end", 0, 4801, 2, 0, ErrSampleOutOfRange},
+		{"backwards", 200, 100, 2, 0, ErrInvalidSampleRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := rampWave(PCM_16, 2, 4800)
+
+			err := wave.TimeStretch(test.start, test.end, test.stretch)
+			if err != test.err {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+
+			want := uint32(4800)
+			if err == nil {
+				want += test.frames - (test.end - test.start)
+			}
+
+			if wave.Frames() != want {
+				t.Errorf("got %d frames, want %d", wave.Frames(), want)
+			}
+		})
+	}
+}
+
+func TestTimeStretchTooLong(t *testing.T) {
+	// only the size of the file matters, so it has no data to go with it
+	wave := CreateWave(PCM_FLOAT64, 8, 48000)
+	*wave.Fact = FactChunk(math.MaxUint32 / 64)
+
+	err := wave.TimeStretch(0, 48000, 2)
+	if err != ErrInvalidStretch {
+		t.Errorf("got error %v, want %v", err, ErrInvalidStretch)
+	}
+}
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   uint32
+		end     uint32
+		frames  []float64
+		markers map[string][2]uint32
+	}{
+		{
+			"around a region",
+			2, 8,
+			[]float64{0, 1, 7, 6, 5, 4, 3, 2, 8, 9},
+			map[string][2]uint32{"point 2": {7, 0}, "region": {4, 3}, "point 7": {2, 0}, "tail": {8, 2}},
+		},
+		{
+			"over part of a region",
+			4, 10,
+			[]float64{0, 1, 2, 3, 9, 8, 7, 6, 5, 4},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {6, 0}, "tail": {4, 2}},
+		},
+		{
+			"empty range",
+			5, 5,
+			[]float64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {7, 0}, "tail": {8, 2}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := editWave()
+
+			err := wave.Reverse(test.start, test.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			frames, markers := editState(wave)
+			if fmt.Sprint(frames) != fmt.Sprint(test.frames) {
+				t.Errorf("got frames %v, want %v", frames, test.frames)
+			}
+
+			if fmt.Sprint(markers) != fmt.Sprint(test.markers) {
+				t.Errorf("got markers %v, want %v", markers, test.markers)
+			}
+		})
+	}
+}
+
+func TestVarispeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   uint32
+		end     uint32
+		speed   float64
+		frames  uint32
+		markers map[string][2]uint32
+		err     error
+	}{
+		{
+			"normal speed", 2, 8, 1, 10,
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 3}, "point 7": {7, 0}, "tail": {8, 2}}, nil,
+		},
+		{
+			"half speed", 2, 8, 0.5, 16,
+			map[string][2]uint32{"point 2": {2, 0}, "region": {4, 6}, "point 7": {12, 0}, "tail": {14, 2}}, nil,
+		},
+		{
+			"double speed", 2, 8, 2, 7,
+			map[string][2]uint32{"point 2": {2, 0}, "region": {3, 1}, "point 7": {5, 0}, "tail": {5, 2}}, nil,
+		},
+		{"zero", 2, 8, 0, 10, nil, ErrInvalidSpeed},
+		{"too slow", 2, 8, MIN_SPEED / 2, 10, nil, ErrInvalidSpeed},
+		{"not a number", 2, 8, math.NaN(), 10, nil, ErrInvalidSpeed},
+		{"past the end", 2, 11, 1, 10, nil, ErrSampleOutOfRange},
+		{"backwards", 8, 2, 1, 10, nil, ErrInvalidSampleRange},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := editWave()
+
+			err := wave.Varispeed(test.start, test.end, func(float64) float64 { return test.speed })
+			if err != test.err {
+				t.Fatalf("got error %v, want %v", err, test.err)
+			}
+
+			if wave.Frames() != test.frames {
+				t.Errorf("got %d frames, want %d", wave.Frames(), test.frames)
+			}
+
+			if _, markers := editState(wave); err == nil && fmt.Sprint(markers) != fmt.Sprint(test.markers) {
+				t.Errorf("got markers %v, want %v", markers, test.markers)
+			}
+		})
+	}
+}
+
+func TestVarispeedNormalSpeed(t *testing.T) {
+	wave := editWave()
+
+	err := wave.Varispeed(0, wave.Frames(), SpeedBreakpoints())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames, _ := editState(wave)
+	for n, frame := range frames {
+		if math.Abs(frame-float64(n)) > 1e-9 {
+			t.Errorf("frame %d is %g, want %d", n, frame, n)
+		}
+	}
+}
+
+func TestVarispeedTooLong(t *testing.T) {
+	// only the range has data, the rest of the file is its size alone
+	wave := CreateWave(PCM_FLOAT64, 8, 48000)
+	wave.resize(100)
+	*wave.Fact = FactChunk(math.MaxUint32/64 - 5000)
+
+	err := wave.Varispeed(0, 100, func(float64) float64 { return MIN_SPEED })
+	if err != ErrInvalidSpeed {
+		t.Errorf("got error %v, want %v", err, ErrInvalidSpeed)
+	}
+}
@@ -0,0 +1,154 @@
+package wave
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+var ErrNoTracks = errors.New("no tracks to merge")
+var ErrNotMono = errors.New("track has more than one channel")
+var ErrIncompatibleFormat = errors.New("tracks have different formats")
+var ErrLengthMismatch = errors.New("tracks have different lengths")
+
+var speakerNames = map[uint32]string{
+	SPEAKER_FRONT_LEFT:            "L",
+	SPEAKER_FRONT_RIGHT:           "R",
+	SPEAKER_FRONT_CENTER:          "C",
+	SPEAKER_LOW_FREQUENCY:         "LFE",
+	SPEAKER_BACK_LEFT:             "Lb",
+	SPEAKER_BACK_RIGHT:            "Rb",
+	SPEAKER_FRONT_LEFT_OF_CENTER:  "Lc",
+	SPEAKER_FRONT_RIGHT_OF_CENTER: "Rc",
+	SPEAKER_BACK_CENTER:           "Cb",
+	SPEAKER_SIDE_LEFT:             "Ls",
+	SPEAKER_SIDE_RIGHT:            "Rs",
+	SPEAKER_TOP_CENTER:            "Tc",
+	SPEAKER_TOP_FRONT_LEFT:        "Tfl",
+	SPEAKER_TOP_FRONT_CENTER:      "Tfc",
+	SPEAKER_TOP_FRONT_RIGHT:       "Tfr",
+	SPEAKER_TOP_BACK_LEFT:         "Tbl",
+	SPEAKER_TOP_BACK_CENTER:       "Tbc",
+	SPEAKER_TOP_BACK_RIGHT:        "Tbr",
+}
+
+// a name for each channel, from the most specific metadata available
+func (wave *WaveFile) TrackNames() []string {
+	names := make([]string, wave.Fmt.Channels)
+
+	for n, speaker := range wave.Fmt.Speakers() {
+		var track *IxmlTrack
+		if wave.Ixml != nil {
+			track = wave.Ixml.Track(uint16(n))
+		}
+
+		if track != nil && track.Name != "" {
+			names[n] = track.Name
+		} else if wave.Fmt.Channels > 1 && speakerNames[speaker] != "" {
+			names[n] = speakerNames[speaker]
+		} else {
+			names[n] = fmt.Sprintf("Track %d", n+1)
+		}
+	}
+
+	return names
+}
+
+// explode a multichannel file into one mono file per channel
+func (wave *WaveFile) SplitChannels() ([]*WaveFile, error) {
+	tracks := make([]*WaveFile, wave.Fmt.Channels)
+
+	for channel := range wave.Fmt.Channels {
+		matrix, err := SplitMatrix(wave.Fmt.Channels, channel)
+		if err != nil {
+			return nil, err
+		}
+
+		track, err := wave.Remix(matrix, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		if wave.Ixml != nil {
+			track.Ixml = wave.Ixml.forChannel(channel)
+		}
+
+		tracks[channel] = track
+	}
+
+	return tracks, nil
+}
+
+// interleave equal length mono files into one multichannel file
+func MergeChannels(tracks []*WaveFile, channelMask uint32) (*WaveFile, error) {
+	if len(tracks) == 0 {
+		return nil, ErrNoTracks
+	} else if len(tracks) > math.MaxUint16 {
+		return nil, ErrInvalidMatrix
+	}
+
+	first := tracks[0].Fmt
+	sampleCount := tracks[0].Fact.Samples()
+
+	for _, track := range tracks {
+		if track.Fmt.Channels != 1 {
+			return nil, ErrNotMono
+		} else if track.Fmt.Format != first.Format || track.Fmt.SamplesPerSec != first.SamplesPerSec {
+			return nil, ErrIncompatibleFormat
+		} else if track.Fact.Samples() != sampleCount {
+			return nil, ErrLengthMismatch
+		}
+	}
+
+	if channelMask != 0 && len(tracks) != bits.OnesCount32(channelMask) {
+		return nil, ErrInvalidMatrix
+	}
+
+	merged := CreateWave(first.Format, uint16(len(tracks)), first.SamplesPerSec)
+	merged.Fmt.ChannelMask = channelMask
+	merged.resize(sampleCount)
+	merged.Ixml = mergedIxml(tracks)
+
+	for channel, track := range tracks {
+		samples, err := track.GetSamples(0, 0, sampleCount)
+		if err != nil {
+			return nil, err
+		}
+
+		err = merged.SetSamples(uint16(channel), 0, samples)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
+
+// list every track in one iXML chunk, taking the rest from the first track with iXML
+func mergedIxml(tracks []*WaveFile) *IxmlChunk {
+	var merged *IxmlChunk
+	ixmlTracks := make([]IxmlTrack, len(tracks))
+
+	for n, track := range tracks {
+		ixmlTracks[n] = IxmlTrack{Name: track.TrackNames()[0]}
+
+		if track.Ixml == nil {
+			continue
+		}
+
+		if merged == nil {
+			merged = track.Ixml.clone()
+		}
+
+		if ixmlTrack := track.Ixml.Track(0); ixmlTrack != nil {
+			ixmlTracks[n] = *ixmlTrack
+		}
+	}
+
+	if merged != nil {
+		merged.SetTracks(ixmlTracks)
+	}
+
+	return merged
+}
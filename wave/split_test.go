@@ -0,0 +1,108 @@
+package wave
+
+import "testing"
+
+func TestSplitAndMergeChannels(t *testing.T) {
+	for _, format := range []WaveFormat{PCM_8, PCM_16, PCM_24, PCM_FLOAT32} {
+		poly := rampWave(format, 3, 20)
+
+		tracks, err := poly.SplitChannels()
+		if err != nil {
+			t.Fatal(err)
+		} else if len(tracks) != 3 {
+			t.Fatalf("format %d: got %d tracks, want 3", format, len(tracks))
+		}
+
+		for channel, track := range tracks {
+			if track.Fmt.Channels != 1 || track.Fmt.Format != format || track.Frames() != 20 {
+				t.Fatalf("format %d: track %d is %+v with %d frames", format, channel, track.Fmt, track.Frames())
+			}
+
+			got, _ := track.GetSamples(0, 0, 20)
+			want, _ := poly.GetSamples(uint16(channel), 0, 20)
+			for n := range want {
+				if got[n] != want[n] {
+					t.Fatalf("format %d: track %d frame %d is %f, want %f", format, channel, n, got[n], want[n])
+				}
+			}
+		}
+
+		merged, err := MergeChannels(tracks, 0)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(merged.Data) != string(poly.Data) {
+			t.Errorf("format %d: merged data differs from the original", format)
+		}
+	}
+}
+
+func TestMergeChannelsErrors(t *testing.T) {
+	mono := rampWave(PCM_16, 1, 10)
+
+	shorter := rampWave(PCM_16, 1, 9)
+	otherFormat := rampWave(PCM_24, 1, 10)
+	otherRate := rampWave(PCM_16, 1, 10)
+	otherRate.Fmt.SamplesPerSec = 48000
+
+	tests := []struct {
+		name        string
+		tracks      []*WaveFile
+		channelMask uint32
+		want        error
+	}{
+		{"no tracks", nil, 0, ErrNoTracks},
+		{"stereo track", []*WaveFile{mono, rampWave(PCM_16, 2, 10)}, 0, ErrNotMono},
+		{"different format", []*WaveFile{mono, otherFormat}, 0, ErrIncompatibleFormat},
+		{"different rate", []*WaveFile{mono, otherRate}, 0, ErrIncompatibleFormat},
+		{"different length", []*WaveFile{mono, shorter}, 0, ErrLengthMismatch},
+		{"mask for the wrong count", []*WaveFile{mono, mono}, LAYOUT_5_1, ErrInvalidMatrix},
+		{"mask", []*WaveFile{mono, mono}, LAYOUT_STEREO, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, err := MergeChannels(test.tracks, test.channelMask)
+			if err != test.want {
+				t.Fatalf("got error %v, want %v", err, test.want)
+			} else if err == nil && merged.Fmt.ChannelMask != test.channelMask {
+				t.Errorf("got mask %#x, want %#x", merged.Fmt.ChannelMask, test.channelMask)
+			}
+		})
+	}
+}
+
+func TestTrackNames(t *testing.T) {
+	surround := rampWave(PCM_16, 6, 1)
+	surround.Fmt.ChannelMask = LAYOUT_5_1
+
+	named := rampWave(PCM_16, 2, 1)
+	named.Ixml = &IxmlChunk{}
+	named.Ixml.SetTracks([]IxmlTrack{{Name: "Boom"}, {}})
+
+	tests := []struct {
+		name string
+		wave *WaveFile
+		want []string
+	}{
+		{"mono", rampWave(PCM_16, 1, 1), []string{"Track 1"}},
+		{"stereo", rampWave(PCM_16, 2, 1), []string{"L", "R"}},
+		{"surround", surround, []string{"L", "R", "C", "LFE", "Lb", "Rb"}},
+		{"iXML, falling back on speakers", named, []string{"Boom", "R"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names := test.wave.TrackNames()
+			if len(names) != len(test.want) {
+				t.Fatalf("got %q, want %q", names, test.want)
+			}
+
+			for n := range names {
+				if names[n] != test.want[n] {
+					t.Errorf("got %q, want %q", names, test.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -7,9 +7,28 @@ import (
 )
 
 type WaveFile struct {
-	Fmt  *FmtChunk
-	Fact *FactChunk
-	Data DataChunk
+	Fmt        *FmtChunk
+	Fact       *FactChunk
+	Data       DataChunk
+	Markers    Markers
+	Sampler    *SamplerChunk
+	Broadcast  *BroadcastChunk
+	Ixml       *IxmlChunk
+	Acid       *AcidChunk
+	Instrument *InstrumentChunk
+	Id3        *Id3Tag
+	Info       Info
+	Peak       *PeakChunk
+	Level      *LevelChunk
+	Layout     ChunkLayout
+}
+
+// chunks read separately that combine into one field of a WaveFile
+type pendingChunks struct {
+	cue   CueChunk
+	adtl  *riff.ListChunk[riff.Chunk]
+	order []string
+	junk  *riff.FillerChunk
 }
 
 var ErrMissingFmt = errors.New("wave file missing format chunk")
@@ -33,13 +52,26 @@ func CreateWave(format WaveFormat, channels uint16, samplesPerSec uint32) *WaveF
 	}
 }
 
+// grow or shrink the data chunk to a number of samples, keeping what fits
+func (wave *WaveFile) resize(sampleCount uint32) {
+	data := DataChunk(wave.silence(sampleCount))
+	copy(data, wave.Data)
+
+	wave.Data = data
+	*wave.Fact = FactChunk(sampleCount)
+}
+
 func deserializeWave(reader io.Reader, size uint32) (riff.Chunk, error) {
 	wave := &WaveFile{}
+	pending := &pendingChunks{}
 
 	for size > 0 {
-		chunkSize, err := deserializeWaveChunk(reader, wave)
+		chunkSize, err := deserializeWaveChunk(reader, wave, pending)
 		if err != nil {
 			return nil, err
+		} else if chunkSize == size+1 {
+			// the last chunk is missing its pad byte
+			break
 		} else if chunkSize > size {
 			return nil, riff.ErrReadTooMuch
 		}
@@ -47,7 +79,7 @@ func deserializeWave(reader io.Reader, size uint32) (riff.Chunk, error) {
 		size -= chunkSize
 	}
 
-	if wave.Fmt.Format == UNKNOWN_FORMAT {
+	if wave.Fmt == nil {
 		return nil, ErrMissingFmt
 	} else if wave.Data == nil {
 		return nil, ErrMissingData
@@ -59,11 +91,26 @@ func deserializeWave(reader io.Reader, size uint32) (riff.Chunk, error) {
 		wave.Fact = &chunk
 	}
 
+	wave.Markers = markersFromChunks(pending.cue, pending.adtl)
+	wave.Layout.original = pending.order
+	wave.Layout.junk = pending.junk
+
 	return wave, nil
 }
-func deserializeWaveChunk(reader io.Reader, waveFile *WaveFile) (uint32, error) {
-	chunk, err := riff.DeserializeChunk(reader,
+
+// read the next chunk into the file, returning the space it took on disk
+func deserializeWaveChunk(reader io.Reader, waveFile *WaveFile, pending *pendingChunks) (uint32, error) {
+	// what the chunk re-encodes to can differ from what was read, such as padding or a cbSize of zero
+	var paddedSize uint32
+
+	_, err := riff.DeserializeChunk(reader,
 		func(reader io.Reader, id riff.FourCC, size uint32) (riff.Chunk, error) {
+			paddedSize = 8 + size + size%2
+
+			if id != riff.ListChunkId {
+				pending.order = append(pending.order, layoutKey(id, ""))
+			}
+
 			switch id {
 			case fmtChunkId:
 				fmtChunk, err := fmtDeserializer(reader, id, size)
@@ -80,6 +127,89 @@ func deserializeWaveChunk(reader io.Reader, waveFile *WaveFile) (uint32, error)
 				waveFile.Data = waveData
 				return waveData, err
 
+			case samplerChunkId:
+				samplerChunk, err := samplerDeserializer(reader, id, size)
+				waveFile.Sampler = samplerChunk
+				return samplerChunk, err
+
+			case broadcastChunkId:
+				broadcastChunk, err := broadcastDeserializer(reader, id, size)
+				waveFile.Broadcast = broadcastChunk
+				return broadcastChunk, err
+
+			case ixmlChunkId:
+				ixmlChunk, err := ixmlDeserializer(reader, id, size)
+				waveFile.Ixml = ixmlChunk
+				return ixmlChunk, err
+
+			case acidChunkId:
+				acidChunk, err := acidDeserializer(reader, id, size)
+				waveFile.Acid = acidChunk
+				return acidChunk, err
+
+			case instrumentChunkId:
+				instrumentChunk, err := instrumentDeserializer(reader, id, size)
+				waveFile.Instrument = instrumentChunk
+				return instrumentChunk, err
+
+			case id3ChunkId, id3UpperChunkId:
+				id3Tag, err := id3Deserializer(reader, id, size)
+				waveFile.Id3 = id3Tag
+				return id3Tag, err
+
+			case peakChunkId:
+				peakChunk, err := peakDeserializer(reader, id, size)
+				waveFile.Peak = peakChunk
+				return peakChunk, err
+
+			case levelChunkId:
+				levelChunk, err := levelDeserializer(reader, id, size)
+				waveFile.Level = levelChunk
+				return levelChunk, err
+
+			case riff.JunkChunkId:
+				_, err := riff.IgnoreDeserializer(reader, id, size)
+				if pending.junk == nil {
+					pending.junk = &riff.FillerChunk{ChunkId: id, DataSize: size}
+				}
+				return pending.junk, err
+
+			case cueChunkId:
+				cueChunk, err := cueDeserializer(reader, id, size)
+				pending.cue = cueChunk
+				return cueChunk, err
+
+			case riff.ListChunkId:
+				if size < 4 {
+					return nil, riff.ErrUnexpectedEnd
+				}
+
+				listType, err := riff.DeserializeFourCC(reader)
+				if err != nil {
+					return nil, err
+				}
+
+				pending.order = append(pending.order, layoutKey(id, listType))
+
+				switch listType {
+				case adtlListType:
+					adtl, err := riff.ListElementsDeserializer(reader, listType, size-4, adtlDeserializer)
+					pending.adtl = adtl
+					return adtl, err
+
+				case infoListType:
+					info, err := riff.ListElementsDeserializer(reader, listType, size-4, infoDeserializer)
+					if err != nil {
+						return nil, err
+					}
+
+					waveFile.Info = infoFromList(info)
+					return info, nil
+				}
+
+				_, err = riff.IgnoreDeserializer(reader, id, size-4)
+				return riff.IgnoreChunk(size), err
+
 			default:
 				return riff.IgnoreDeserializer(reader, id, size)
 			}
@@ -89,7 +219,7 @@ func deserializeWaveChunk(reader io.Reader, waveFile *WaveFile) (uint32, error)
 		return 0, err
 	}
 
-	return chunk.Size(), err
+	return paddedSize, nil
 }
 
 func (chunk *WaveFile) Serialize(writer io.Writer) error {
@@ -98,21 +228,16 @@ func (chunk *WaveFile) Serialize(writer io.Writer) error {
 		return err
 	}
 
-	err = chunk.Fmt.Serialize(writer)
-	if err != nil {
-		return err
-	}
-
-	if chunk.Fact != nil {
-		err = chunk.Fact.Serialize(writer)
+	for _, child := range chunk.chunks() {
+		err = child.Serialize(writer)
 		if err != nil {
 			return err
 		}
-	}
 
-	err = chunk.Data.Serialize(writer)
-	if err != nil {
-		return err
+		err = riff.SerializePadding(writer, child.Size())
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -121,13 +246,84 @@ func (chunk *WaveFile) Serialize(writer io.Writer) error {
 func (chunk *WaveFile) Size() uint32 {
 	var size uint32 = 12
 
-	size += chunk.Fmt.Size()
+	for _, child := range chunk.chunks() {
+		size += riff.PaddedSize(child)
+	}
+
+	return size
+}
+
+// every chunk to save, in file order
+func (chunk *WaveFile) chunks() []riff.Chunk {
+	chunks := []layoutChunk{}
+	add := func(key string, child riff.Chunk) {
+		chunks = append(chunks, layoutChunk{key, child})
+	}
+
+	// space a file was read with stays where it was, unless a new reservation takes its place
+	if chunk.Layout.junk != nil && chunk.Layout.Order == ORDER_ORIGINAL && !chunk.Layout.ReserveJunk {
+		add(riff.JunkChunkId, chunk.Layout.junk)
+	}
+
+	// broadcast systems expect bext ahead of the audio
+	if chunk.Broadcast != nil {
+		add(broadcastChunkId, chunk.Broadcast)
+	}
+
+	add(fmtChunkId, chunk.Fmt)
 
 	if chunk.Fact != nil {
-		size += chunk.Fact.Size()
+		add(factChunkId, chunk.Fact)
 	}
 
-	size += chunk.Data.Size()
+	// readers want the overview before they reach the audio
+	if chunk.Peak != nil {
+		add(peakChunkId, chunk.Peak)
+	}
 
-	return size
+	if chunk.Level != nil {
+		add(levelChunkId, chunk.Level)
+	}
+
+	add(dataChunkId, chunk.Data)
+
+	if chunk.Sampler != nil {
+		add(samplerChunkId, chunk.Sampler)
+	}
+
+	if chunk.Instrument != nil {
+		add(instrumentChunkId, chunk.Instrument)
+	}
+
+	if chunk.Acid != nil {
+		add(acidChunkId, chunk.Acid)
+	}
+
+	if chunk.Ixml != nil {
+		add(ixmlChunkId, chunk.Ixml)
+	}
+
+	if info := chunk.Info.chunk(); info != nil {
+		add(layoutKey(riff.ListChunkId, infoListType), info)
+	}
+
+	if chunk.Id3 != nil {
+		id := chunk.Id3.chunkId
+		if id == "" {
+			id = id3ChunkId
+		}
+
+		add(string(id), chunk.Id3)
+	}
+
+	cue, adtl := chunk.Markers.chunks()
+	if cue != nil {
+		add(cueChunkId, cue)
+	}
+
+	if adtl != nil {
+		add(layoutKey(riff.ListChunkId, adtlListType), adtl)
+	}
+
+	return chunk.Layout.arrange(chunks)
 }
@@ -0,0 +1,125 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"wave-edit/riff"
+)
+
+// a chunk as stored in a file, with its pad byte
+func rawChunk(id string, data []byte) []byte {
+	chunk := binary.LittleEndian.AppendUint32([]byte(id), uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+
+	return chunk
+}
+
+// the fields of a struct as stored by riff.SerializeStruct
+func rawStruct(value any) []byte {
+	var buffer bytes.Buffer
+	riff.SerializeStruct(&buffer, value)
+	return buffer.Bytes()
+}
+
+// a LIST chunk of a type holding chunks
+func rawList(listType string, chunks ...[]byte) []byte {
+	return rawChunk(riff.ListChunkId, append([]byte(listType), bytes.Join(chunks, nil)...))
+}
+
+func rawFmt(formatTag, channels uint16, samplesPerSec uint32, bitsPerSample uint16, extra ...byte) []byte {
+	blockSize := channels * bitsPerSample / 8
+
+	data := binary.LittleEndian.AppendUint16(nil, formatTag)
+	data = binary.LittleEndian.AppendUint16(data, channels)
+	data = binary.LittleEndian.AppendUint32(data, samplesPerSec)
+	data = binary.LittleEndian.AppendUint32(data, samplesPerSec*uint32(blockSize))
+	data = binary.LittleEndian.AppendUint16(data, blockSize)
+	data = binary.LittleEndian.AppendUint16(data, bitsPerSample)
+
+	return rawChunk(fmtChunkId, append(data, extra...))
+}
+
+// a RIFF WAVE file of chunks
+func rawWave(chunks ...[]byte) []byte {
+	body := append([]byte("WAVE"), bytes.Join(chunks, nil)...)
+	return append(binary.LittleEndian.AppendUint32([]byte("RIFF"), uint32(len(body))), body...)
+}
+
+// a RIFF WAVE file of four frames of stereo 16 bit silence with extra chunks after the data
+func rawStereoWave(extra ...[]byte) []byte {
+	chunks := [][]byte{rawFmt(PCM_FORMAT_TAG, 2, 44100, 16), rawChunk(dataChunkId, make([]byte, 16))}
+	return rawWave(append(chunks, extra...)...)
+}
+
+func loadWaveError(data []byte) (*WaveFile, error) {
+	chunk, err := riff.DeserializerRiff(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk.(*WaveFile), nil
+}
+
+func loadWave(t *testing.T, data []byte) *WaveFile {
+	t.Helper()
+
+	wave, err := loadWaveError(data)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	return wave
+}
+
+func saveWave(t *testing.T, wave *WaveFile) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	err := wave.Serialize(&buffer)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	} else if uint32(buffer.Len()) != wave.Size() {
+		t.Fatalf("saved %d bytes, Size is %d", buffer.Len(), wave.Size())
+	}
+
+	return buffer.Bytes()
+}
+
+// save and load again
+func roundTrip(t *testing.T, wave *WaveFile) *WaveFile {
+	t.Helper()
+	return loadWave(t, saveWave(t, wave))
+}
+
+func TestUnknownChunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		chunk []byte
+	}{
+		{"even", rawChunk("abcd", []byte{1, 2})},
+		{"odd", rawChunk("abcd", []byte{1, 2, 3})},
+		{"empty", rawChunk("abcd", nil)},
+		{"unknown list", rawList("abcd", rawChunk("efgh", []byte{1}))},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			wave := loadWave(t, rawStereoWave(test.chunk))
+			if wave.Frames() != 4 {
+				t.Errorf("got %d frames, want 4", wave.Frames())
+			}
+		})
+	}
+}
+
+func TestMissingFinalPadByte(t *testing.T) {
+	data := rawStereoWave(rawChunk("abcd", []byte{1, 2, 3}))
+	data = data[:len(data)-1]
+	binary.LittleEndian.PutUint32(data[4:8], uint32(len(data)-8))
+
+	loadWave(t, data)
+}